@@ -3,6 +3,8 @@ package sqlseeder
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/tangzero/inflector"
@@ -16,6 +18,13 @@ type AdapterInterface interface {
 	// WrapWithSingleQoute wraps a value in single quotes (e.g., 'value').
 	WrapWithSingleQoute(value string) string
 
+	// FormatValue renders a typed cell value (string, number, bool, nil, object, array or RawSQL)
+	// as an SQL literal.
+	FormatValue(value interface{}) string
+
+	// QuoteIdentifier quotes the parts of an identifier that require quoting in the adapter dialect.
+	QuoteIdentifier(identifier string) string
+
 	// ParseManyToManyColumns parses a list of many-to-many column names and returns
 	// a map of ManyToManyRelation structs.
 	// This map will hold the column name as a key and an object of ManyToManyRelation as the value.
@@ -27,8 +36,20 @@ type AdapterInterface interface {
 
 	// IsOneToMany checks if a column represents a one-to-many relationship.
 	IsOneToMany(columnName string) bool
+	// IsManyToMany checks if a column represents a many-to-many relationship.
+	IsManyToMany(columnName string) bool
+	// IsChildRows checks if a column holds the child rows of another table.
+	IsChildRows(columnName string) bool
+	// ParseChildRows parses a child rows column name and returns a ChildRowsRelation struct.
+	ParseChildRows(columnName string, schemaName string, tableName string) (ChildRowsRelation, error)
 	// IsHashedColumn checks if a column represents a password so it should be hashed
 	IsHashedColumn(columnName string) bool
+	// IsEmbeddingColumn checks if a column holds the embedding of another column.
+	IsEmbeddingColumn(columnName string) bool
+	// IsRawColumn checks if the values of a column are raw SQL expressions.
+	IsRawColumn(columnName string) bool
+	// ParseEmbedding parses an embedding column name and returns the target and source columns.
+	ParseEmbedding(columnName string) (EmbeddingRelation, error)
 	// GetFullTableName returns the full table name with the schema (if provided).
 	GetFullTableName(schemaName string, tableName string) string
 
@@ -36,27 +57,105 @@ type AdapterInterface interface {
 	GetPrimaryKeyFromTableName(tableName string) string
 	IsArrayColumn(columnName string) bool
 
-	// SplitColumnsToStatemntParts splits the columns of a row into root columns and
-	// many-to-many columns.
+	// SplitColumnsToStatemntParts splits the columns of a row into root columns,
+	// many-to-many columns and child rows columns.
 	SplitColumnsToStatemntParts(row map[string]interface{}) ColumnsStatemntParts
 
+	// SplitOrderedColumnsToStatemntParts splits a list of columns into root columns,
+	// many-to-many columns and child rows columns keeping their order.
+	SplitOrderedColumnsToStatemntParts(columns []string) ColumnsStatemntParts
+
 	// ParseOneToMany parses a one-to-many column name and returns an
 	// OneToManyRelation struct.
 	ParseOneToMany(columnName string, tableName string) (OneToManyRelation, error)
 }
 
+// RawColumnSuffix marks a column whose values are written as raw SQL expressions.
+const RawColumnSuffix = "!raw"
+
+// EmbeddingDelimiter separates an embedding column from the column its text is read from.
+const EmbeddingDelimiter = "~"
+
+// AutoCreateSuffix marks the table of a lookup header whose missing rows are inserted before the lookup,
+// e.g. category_id**categories+**category_name.
+const AutoCreateSuffix = "+"
+
+// ChildRowsDelimiter separates the child table of a child rows column from the parent column
+// its rows look the parent up by, e.g. order_lines>order_number.
+const ChildRowsDelimiter = ">"
+
+// LookupWildcard is the lookup value that selects every row of the related table instead of searching for one.
+const LookupWildcard = "*"
+
+// CompositeKeyDelimiter separates the search columns of a composite lookup header
+// and the values of its cells (e.g. country_code+city_name and EG+Cairo).
+const CompositeKeyDelimiter = "+"
+
+// ManyToManyAttributeDelimiter separates the attribute names of a many-to-many header
+// and the attribute values of its cell items (e.g. tag1:1:true).
+const ManyToManyAttributeDelimiter = ":"
+
 // Adapter implements the AdapterInterface.
 type Adapter struct {
 	OneToManyDelimiter  string
 	ManyToManyDelimiter string
+	Dialect             Dialect
+}
+
+// plainIdentifierPattern matches the identifiers written without quotes, their case is left to the database.
+var plainIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// headerIdentifierPattern matches the (optionally schema qualified) identifiers accepted in relation headers.
+var headerIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// reservedWords lists the keywords that can't be used as unquoted identifiers: the reserved key words of
+// PostgreSQL and the common reserved words of MySQL, SQLite and SQL Server.
+var reservedWords = map[string]bool{
+	"add": true, "all": true, "alter": true, "analyse": true, "analyze": true, "and": true, "any": true,
+	"array": true, "as": true, "asc": true, "asymmetric": true, "authorization": true, "begin": true,
+	"between": true, "both": true, "by": true, "case": true, "cast": true, "check": true, "collate": true,
+	"column": true, "commit": true, "constraint": true, "create": true, "cross": true, "current_catalog": true,
+	"current_date": true, "current_role": true, "current_schema": true, "current_time": true,
+	"current_timestamp": true, "current_user": true, "database": true, "default": true, "deferrable": true,
+	"delete": true, "desc": true, "distinct": true, "do": true, "drop": true, "else": true, "end": true,
+	"except": true, "exists": true, "false": true, "fetch": true, "for": true, "foreign": true, "from": true,
+	"full": true, "grant": true, "group": true, "having": true, "if": true, "ilike": true, "in": true,
+	"index": true, "initially": true, "inner": true, "insert": true, "intersect": true, "interval": true,
+	"into": true, "is": true, "join": true, "key": true, "lateral": true, "leading": true, "left": true,
+	"like": true, "limit": true, "localtime": true, "localtimestamp": true, "match": true, "merge": true,
+	"natural": true, "not": true, "null": true, "offset": true, "on": true, "only": true, "or": true,
+	"order": true, "outer": true, "over": true, "partition": true, "placing": true, "primary": true,
+	"range": true, "references": true, "returning": true, "right": true, "row": true, "rows": true,
+	"select": true, "session_user": true, "set": true, "some": true, "symmetric": true, "system_user": true,
+	"table": true, "then": true, "to": true, "top": true, "trailing": true, "true": true, "union": true,
+	"unique": true, "update": true, "user": true, "using": true, "values": true, "variadic": true,
+	"view": true, "when": true, "where": true, "window": true, "with": true,
+}
+
+// AdapterOption configures an Adapter created by NewAdapter.
+type AdapterOption func(*Adapter)
+
+// WithDialect sets the dialect of the adapter, a nil dialect keeps PostgresDialect.
+func WithDialect(dialect Dialect) AdapterOption {
+	return func(a *Adapter) {
+		if dialect != nil {
+			a.Dialect = dialect
+		}
+	}
 }
 
 // NewAdapter creates a new Adapter with the specified delimiters.
-func NewAdapter(oneToManyDelimiter string, manyToManyDelimiter string) AdapterInterface {
-	return &Adapter{
+// The dialect defaults to PostgresDialect, use WithDialect to pick another one.
+func NewAdapter(oneToManyDelimiter string, manyToManyDelimiter string, options ...AdapterOption) AdapterInterface {
+	adapter := &Adapter{
 		OneToManyDelimiter:  oneToManyDelimiter,
 		ManyToManyDelimiter: manyToManyDelimiter,
+		Dialect:             PostgresDialect{},
+	}
+	for _, option := range options {
+		option(adapter)
 	}
+	return adapter
 }
 
 // IsArrayColumn checks if a column represents an array.
@@ -83,22 +182,104 @@ func (a *Adapter) IsHashedColumn(columnName string) bool {
 	return strings.Contains(columnName, "#") && len(strings.Split(columnName, "#")) == 2
 }
 
+// IsRawColumn checks if the values of a column are raw SQL expressions (e.g. created_at!raw).
+func (a *Adapter) IsRawColumn(columnName string) bool {
+	return strings.HasSuffix(columnName, RawColumnSuffix)
+}
+
+// IsEmbeddingColumn checks if a column holds the embedding of another column.
+func (a *Adapter) IsEmbeddingColumn(columnName string) bool {
+	return strings.Contains(columnName, EmbeddingDelimiter) && len(strings.Split(columnName, EmbeddingDelimiter)) == 2
+}
+
+// ParseEmbedding parses an embedding column name.
+//
+// Formula: <target_column><EmbeddingDelimiter><source_column>
+// Example: description_embedding~description
+// Should return:
+//
+//	EmbeddingRelation{
+//	  Column:       "description_embedding",
+//	  SourceColumn: "description",
+//	}
+func (a *Adapter) ParseEmbedding(columnName string) (EmbeddingRelation, error) {
+	parts := strings.Split(columnName, EmbeddingDelimiter)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return EmbeddingRelation{}, fmt.Errorf("not valid embedding column name: %s", columnName)
+	}
+	return EmbeddingRelation{
+		Column:       strings.TrimSpace(parts[0]),
+		SourceColumn: strings.TrimSpace(parts[1]),
+	}, nil
+}
+
 // IsOneToMany checks if a column represents a one-to-many relationship.
 func (a *Adapter) IsOneToMany(columnName string) bool {
 	return strings.Contains(columnName, a.OneToManyDelimiter) && !strings.Contains(columnName, a.ManyToManyDelimiter)
 }
 
+// IsManyToMany checks if a column represents a many-to-many relationship.
+func (a *Adapter) IsManyToMany(columnName string) bool {
+	return strings.Contains(columnName, a.ManyToManyDelimiter)
+}
+
+// IsChildRows checks if a column holds the child rows of another table (e.g. order_lines>order_number).
+func (a *Adapter) IsChildRows(columnName string) bool {
+	return strings.Contains(columnName, ChildRowsDelimiter)
+}
+
 // WrapWithSingleQoute wraps a value in single quotes.
 func (a *Adapter) WrapWithSingleQoute(value string) string {
 	if value == "" || value == "NULL" || value == "null" {
 		return "NULL"
 	}
-	if strings.Contains(value, "SELECT") {
-		return value
-	}
 	return fmt.Sprintf("'%s'", value)
 }
 
+// FormatValue renders a typed cell value as an SQL literal of the adapter dialect.
+//
+// Examples (postgres):
+//   - "Toys"                       =>  'Toys'
+//   - 12.5, json.Number("12.5")    =>  12.5
+//   - true                         =>  TRUE
+//   - nil                          =>  NULL
+//   - map{"color": "red"}          =>  '{"color":"red"}'::jsonb
+//   - []interface{}{"a", 1}        =>  ARRAY['a', 1]
+//   - RawSQL("(SELECT ...)")       =>  (SELECT ...)
+func (a *Adapter) FormatValue(value interface{}) string {
+	return FormatLiteral(a.Dialect, value)
+}
+
+// QuoteIdentifier quotes each part of a (possibly schema qualified) identifier that
+// would not be accepted unquoted, i.e. reserved words and names with other characters than
+// letters, digits and underscores. Mixed case names are not quoted so the database folds them as usual.
+//
+// Examples (postgres):
+//   - "products"         =>  products
+//   - "public.order"     =>  public."order"
+//   - "productName"      =>  productName
+//   - "unit price"       =>  "unit price"
+func (a *Adapter) QuoteIdentifier(identifier string) string {
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		if !plainIdentifierPattern.MatchString(part) || reservedWords[strings.ToLower(part)] {
+			parts[i] = a.Dialect.QuoteIdentifier(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// validateHeaderIdentifiers makes sure the table and column names parsed from a relation header
+// are plain identifiers so a header can't inject SQL into the generated lookups.
+func validateHeaderIdentifiers(columnName string, identifiers ...string) error {
+	for _, identifier := range identifiers {
+		if !headerIdentifierPattern.MatchString(identifier) {
+			return fmt.Errorf("invalid identifier '%s' in column header '%s': only letters, digits, underscores and a schema prefix are allowed", identifier, columnName)
+		}
+	}
+	return nil
+}
+
 // ParseManyToMany parses a many-to-many relationship column name.
 //
 // Formula: <joining_table_primary_key><ManyToManyDelimiter><joining_table_name><ManyToManyDelimiter><second_table_name><ManyToManyDelimiter><second_table_search_column><ManyToManyDelimiter><first_table_search_column>
@@ -113,16 +294,39 @@ func (a *Adapter) WrapWithSingleQoute(value string) string {
 //	  SecondSearchColumn: "tag_name",
 //	  Columns:            ["product_id**products**product_name", "tag_id**tags**tag_name"],
 //	}
+//
+// A second table suffixed with AutoCreateSuffix (tags+) inserts the missing tags before the join rows.
+// An optional sixth part lists extra join table columns separated by ManyToManyAttributeDelimiter,
+// e.g. tag_id***product_tags***tags***tag_name***product_name***sort_order:is_primary,
+// they are added to Attributes and Columns and filled from cells like tag1:1:true|tag2:2:false.
 func (a *Adapter) ParseManyToMany(columnName string, schemaName string, tableName string) (ManyToManyRelation, error) {
 	parts := strings.Split(columnName, a.ManyToManyDelimiter)
 	response := ManyToManyRelation{}
-	if len(parts) != 5 {
+	if len(parts) != 5 && len(parts) != 6 {
 		return response, fmt.Errorf("not valid many to many column name: %s", columnName)
 	}
+	var attributes []string
+	if len(parts) == 6 {
+		attributes = strings.Split(parts[5], ManyToManyAttributeDelimiter)
+		if err := validateHeaderIdentifiers(columnName, attributes...); err != nil {
+			return response, err
+		}
+		parts = parts[:5]
+	}
+	// the suffix is kept in the second column so that its lookup creates the missing rows
+	secondTable := parts[2]
+	parts[2] = strings.TrimSuffix(parts[2], AutoCreateSuffix)
+	if err := validateHeaderIdentifiers(columnName, parts[0], parts[1], parts[2], parts[4]); err != nil {
+		return response, err
+	}
+	// the second table search column can be composite, see ParseOneToMany
+	if err := validateHeaderIdentifiers(columnName, strings.Split(parts[3], CompositeKeyDelimiter)...); err != nil {
+		return response, err
+	}
 	fullTableName := a.GetFullTableName(schemaName, tableName)
 	firstColumn := fmt.Sprintf("%s%s%s%s%s", a.GetPrimaryKeyFromTableName(tableName), a.OneToManyDelimiter, fullTableName, a.OneToManyDelimiter, parts[4])
-	secondColumn := fmt.Sprintf("%s%s%s%s%s", parts[0], a.OneToManyDelimiter, parts[2], a.OneToManyDelimiter, parts[3])
-	result := []string{firstColumn, secondColumn}
+	secondColumn := fmt.Sprintf("%s%s%s%s%s", parts[0], a.OneToManyDelimiter, secondTable, a.OneToManyDelimiter, parts[3])
+	result := append([]string{firstColumn, secondColumn}, attributes...)
 	response = ManyToManyRelation{
 		Table:              parts[1],
 		FirstTable:         tableName,
@@ -130,6 +334,7 @@ func (a *Adapter) ParseManyToMany(columnName string, schemaName string, tableNam
 		FirstSearchColumn:  parts[4],
 		SecondSearchColumn: parts[3],
 		Columns:            result,
+		Attributes:         attributes,
 	}
 	return response, nil
 }
@@ -148,6 +353,56 @@ func (a *Adapter) ParseManyToManyColumns(columns []string, schemaName string, ta
 
 }
 
+// ParseChildRows parses a child rows column name, its value is an array of objects inserted into
+// the child table with a foreign key looking the parent row up by its search column.
+//
+// Formula: <child_table><ChildRowsDelimiter><parent_search_column>
+// Example: order_lines>order_number
+// Should return:
+//
+//	ChildRowsRelation{
+//	  Schema:             "public", // the schema of the parent table
+//	  Table:              "order_lines",
+//	  ForeignKey:         "order_id", // Assuming the current table is "orders"
+//	  ParentSearchColumn: "order_number",
+//	  Column:             "order_id**public.orders**order_number",
+//	}
+//
+// The foreign key defaults to the primary key of the parent table and can be set with a middle part,
+// e.g. order_lines>parent_order_id>order_number. The child table is in the schema of the parent table
+// unless it is schema qualified, e.g. sales.order_lines>order_number.
+func (a *Adapter) ParseChildRows(columnName string, schemaName string, tableName string) (ChildRowsRelation, error) {
+	parts := strings.Split(columnName, ChildRowsDelimiter)
+	if len(parts) != 2 && len(parts) != 3 {
+		return ChildRowsRelation{}, fmt.Errorf("not valid child rows column name: %s", columnName)
+	}
+	if err := validateHeaderIdentifiers(columnName, parts...); err != nil {
+		return ChildRowsRelation{}, err
+	}
+	foreignKey := a.GetPrimaryKeyFromTableName(tableName)
+	if len(parts) == 3 {
+		foreignKey = parts[1]
+	}
+	parentSearchColumn := parts[len(parts)-1]
+	primaryKey := a.GetPrimaryKeyFromTableName(tableName)
+	fullTableName := a.GetFullTableName(schemaName, tableName)
+	column := fmt.Sprintf("%s%s%s%s%s", foreignKey, a.OneToManyDelimiter, fullTableName, a.OneToManyDelimiter, parentSearchColumn)
+	if foreignKey != primaryKey {
+		column = fmt.Sprintf("%s%s%s%s%s%s%s", foreignKey, a.OneToManyDelimiter, primaryKey, a.OneToManyDelimiter, fullTableName, a.OneToManyDelimiter, parentSearchColumn)
+	}
+	childSchema, childTable := schemaName, parts[0]
+	if schema, table, ok := strings.Cut(parts[0], "."); ok {
+		childSchema, childTable = schema, table
+	}
+	return ChildRowsRelation{
+		Schema:             childSchema,
+		Table:              childTable,
+		ForeignKey:         foreignKey,
+		ParentSearchColumn: parentSearchColumn,
+		Column:             column,
+	}, nil
+}
+
 // ParseOneToMany parses a one-to-many relationship column name.
 //
 // Formula: <primary_key_column><OneToManyDelimiter><table_name><OneToManyDelimiter><search_key_column>
@@ -157,14 +412,29 @@ func (a *Adapter) ParseManyToManyColumns(columns []string, schemaName string, ta
 //	OneToManyRelation{
 //	  Table:      "categories",
 //	  PrimaryKey: "category_id",
+//	  ForeignKey: "category_id",
 //	  SearchKey:  "category_name",
 //	}
+//
+// The search key can list several columns separated by CompositeKeyDelimiter for tables that are
+// unique by more than one column, e.g. city_id**cities**country_code+city_name.
+// A table suffixed with AutoCreateSuffix (categories+) sets AutoCreate.
+// Table and column names must be plain identifiers, anything else is reported as an error.
 func (a *Adapter) ParseOneToMany(columnName string, tableName string) (OneToManyRelation, error) {
 	parts := strings.Split(columnName, a.OneToManyDelimiter)
 	response := OneToManyRelation{}
 	if len(parts) != 3 && len(parts) != 4 {
 		return response, fmt.Errorf("not valid one to many column name: %s", columnName)
 	}
+	tableIndex := len(parts) - 2
+	autoCreate := strings.HasSuffix(parts[tableIndex], AutoCreateSuffix)
+	parts[tableIndex] = strings.TrimSuffix(parts[tableIndex], AutoCreateSuffix)
+	if err := validateHeaderIdentifiers(columnName, parts[:len(parts)-1]...); err != nil {
+		return response, err
+	}
+	if err := validateHeaderIdentifiers(columnName, strings.Split(parts[len(parts)-1], CompositeKeyDelimiter)...); err != nil {
+		return response, err
+	}
 	if len(parts) == 3 {
 		response = OneToManyRelation{
 			ForeignKey: parts[0],
@@ -181,16 +451,34 @@ func (a *Adapter) ParseOneToMany(columnName string, tableName string) (OneToMany
 			SearchKey:  parts[3],
 		}
 	}
+	response.AutoCreate = autoCreate
 
 	return response, nil
 }
 
-// SplitColumnsToStatemntParts splits the columns of a row into root columns and many-to-many columns.
+// SplitColumnsToStatemntParts splits the columns of a row into root columns, many-to-many columns and child rows columns.
+// A map has no order so the columns are sorted alphabetically.
 func (a *Adapter) SplitColumnsToStatemntParts(row map[string]interface{}) ColumnsStatemntParts {
+	columns := make([]string, 0, len(row))
+	for key := range row {
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+	return a.SplitOrderedColumnsToStatemntParts(columns)
+}
+
+// SplitOrderedColumnsToStatemntParts splits a list of columns into root columns, many-to-many columns
+// and child rows columns keeping their order.
+func (a *Adapter) SplitOrderedColumnsToStatemntParts(columns []string) ColumnsStatemntParts {
 	manyToManyColumns := []string{}
+	childRowsColumns := []string{}
 	rootColumns := []string{}
-	for key := range row {
-		if strings.Contains(key, a.ManyToManyDelimiter) {
+	for _, key := range columns {
+		if a.IsChildRows(key) {
+			childRowsColumns = append(childRowsColumns, key)
+			continue
+		}
+		if a.IsManyToMany(key) {
 			manyToManyColumns = append(manyToManyColumns, key)
 			continue
 		}
@@ -199,6 +487,7 @@ func (a *Adapter) SplitColumnsToStatemntParts(row map[string]interface{}) Column
 	return ColumnsStatemntParts{
 		RootColumns:       rootColumns,
 		ManyToManyColumns: manyToManyColumns,
+		ChildRowsColumns:  childRowsColumns,
 	}
 }
 
@@ -1,8 +1,8 @@
 package sqlseeder
 
 import (
+	"encoding/json"
 	"fmt"
-	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -68,6 +68,46 @@ func TestAdapter_ParseManyToMany(t *testing.T) {
 		Columns:            []string{"product_id**public.products**product_name", "tag_id**tags**tag_name"},
 	}
 	require.Equal(t, relation, expected)
+
+	relation, err = adapter.ParseManyToMany("tag_id***product_tags***tags***tag_name***product_name***sort_order:is_primary", "public", "products")
+	require.NoError(t, err)
+	expected.Columns = append(expected.Columns, "sort_order", "is_primary")
+	expected.Attributes = []string{"sort_order", "is_primary"}
+	require.Equal(t, expected, relation)
+
+	_, err = adapter.ParseManyToMany("tag_id***product_tags***tags***tag_name***product_name***sort_order:", "public", "products")
+	require.Error(t, err)
+
+	relation, err = adapter.ParseManyToMany("tag_id***product_tags***tags+***tag_name***product_name", "public", "products")
+	require.NoError(t, err)
+	require.Equal(t, "tags", relation.SecondTable)
+	require.Equal(t, "tag_id**tags+**tag_name", relation.Columns[1])
+}
+
+func TestAdapter_ParseChildRows(t *testing.T) {
+	relation, err := adapter.ParseChildRows("order_lines>order_number", "public", "orders")
+	require.NoError(t, err)
+	require.Equal(t, ChildRowsRelation{
+		Schema:             "public",
+		Table:              "order_lines",
+		ForeignKey:         "order_id",
+		ParentSearchColumn: "order_number",
+		Column:             "order_id**public.orders**order_number",
+	}, relation)
+
+	relation, err = adapter.ParseChildRows("order_lines>parent_order_id>order_number", "public", "orders")
+	require.NoError(t, err)
+	require.Equal(t, "parent_order_id**order_id**public.orders**order_number", relation.Column)
+
+	relation, err = adapter.ParseChildRows("sales.order_lines>order_number", "public", "orders")
+	require.NoError(t, err)
+	require.Equal(t, "sales", relation.Schema)
+	require.Equal(t, "order_lines", relation.Table)
+
+	_, err = adapter.ParseChildRows("order_lines>order_number>", "public", "orders")
+	require.Error(t, err)
+	_, err = adapter.ParseChildRows("order_lines>a>b>c", "public", "orders")
+	require.Error(t, err)
 }
 
 func TestAdapter_ParseOneToMany(t *testing.T) {
@@ -79,12 +119,24 @@ func TestAdapter_ParseOneToMany(t *testing.T) {
 	expected := OneToManyRelation{
 		Table:      "categories",
 		PrimaryKey: "category_id",
+		ForeignKey: "category_id",
 		SearchKey:  "category_name",
 	}
 
 	if relation != expected {
 		t.Errorf("Expected OneToManyRelation to be %+v, but got %+v", expected, relation)
 	}
+
+	relation, err = adapter.ParseOneToMany("city_id**cities**country_code+city_name", "stores")
+	require.NoError(t, err)
+	require.Equal(t, []string{"country_code", "city_name"}, relation.SearchKeys())
+
+	_, err = adapter.ParseOneToMany("city_id**cities**country_code+", "stores")
+	require.Error(t, err)
+
+	relation, err = adapter.ParseOneToMany("category_id**categories+**category_name", "products")
+	require.NoError(t, err)
+	require.Equal(t, OneToManyRelation{Table: "categories", PrimaryKey: "category_id", ForeignKey: "category_id", SearchKey: "category_name", AutoCreate: true}, relation)
 }
 func TestAdapter_SplitColumnsToStatemntParts(t *testing.T) {
 	row := map[string]interface{}{
@@ -96,14 +148,15 @@ func TestAdapter_SplitColumnsToStatemntParts(t *testing.T) {
 
 	parts := adapter.SplitColumnsToStatemntParts(row)
 
-	expectedRootColumns := []string{"id", "name", "category_id"}
+	// rows have no column order so the columns are sorted
+	expectedRootColumns := []string{"category_id", "id", "name"}
 	expectedManyToManyColumns := []string{"tag_id***product_tags***tags***tag_name***product_name"}
-	sort.Strings(parts.RootColumns)
-	sort.Strings(expectedRootColumns)
-	sort.Strings(parts.ManyToManyColumns)
-	sort.Strings(expectedManyToManyColumns)
 	require.Equal(t, parts.RootColumns, expectedRootColumns)
 	require.Equal(t, parts.ManyToManyColumns, expectedManyToManyColumns)
+
+	parts = adapter.SplitOrderedColumnsToStatemntParts([]string{"name", "tag_id***product_tags***tags***tag_name***product_name", "id"})
+	require.Equal(t, []string{"name", "id"}, parts.RootColumns)
+	require.Equal(t, expectedManyToManyColumns, parts.ManyToManyColumns)
 }
 
 func TestAdapter_GetPrimaryKeyFromTableName(t *testing.T) {
@@ -120,3 +173,58 @@ func TestAdapter_GetPrimaryKeyFromTableName(t *testing.T) {
 		t.Errorf("Expected primary key to be '%s', but got '%s'", expected, primaryKey)
 	}
 }
+
+func TestAdapter_FormatValue(t *testing.T) {
+	testCases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{"Kids' Toys", "'Kids'' Toys'"},
+		{"", "NULL"},
+		{nil, "NULL"},
+		{json.Number("12.50"), "12.50"},
+		{12.5, "12.5"},
+		{3, "3"},
+		{true, "TRUE"},
+		{false, "FALSE"},
+		{map[string]interface{}{"note": "it's"}, `'{"note":"it''s"}'::jsonb`},
+		{[]interface{}{"a", json.Number("1"), nil}, "ARRAY['a', 1, NULL]"},
+		{[]interface{}{}, "'{}'"},
+		{RawSQL("(SELECT 1)"), "(SELECT 1)"},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, adapter.FormatValue(tc.value))
+	}
+}
+
+func TestAdapter_ParseEmbedding(t *testing.T) {
+	require.True(t, adapter.IsEmbeddingColumn("description_embedding~description"))
+	require.False(t, adapter.IsEmbeddingColumn("description"))
+
+	relation, err := adapter.ParseEmbedding("description_embedding~description")
+	require.NoError(t, err)
+	require.Equal(t, EmbeddingRelation{Column: "description_embedding", SourceColumn: "description"}, relation)
+
+	_, err = adapter.ParseEmbedding("description_embedding~")
+	require.Error(t, err)
+}
+
+func TestAdapter_ParseRelationsRejectInvalidIdentifiers(t *testing.T) {
+	_, err := adapter.ParseOneToMany("category_id**categories; DROP TABLE users**category_name", "products")
+	require.EqualError(t, err, "invalid identifier 'categories; DROP TABLE users' in column header 'category_id**categories; DROP TABLE users**category_name': only letters, digits, underscores and a schema prefix are allowed")
+
+	_, err = adapter.ParseOneToMany("category_id**store.categories**category_name", "products")
+	require.NoError(t, err)
+
+	_, err = adapter.ParseManyToMany("tag_id***product_tags***tags***tag_name') --***product_name", "public", "products")
+	require.ErrorContains(t, err, "invalid identifier 'tag_name') --'")
+}
+
+func TestAdapter_QuoteIdentifier(t *testing.T) {
+	require.Equal(t, "products", adapter.QuoteIdentifier("products"))
+	require.Equal(t, "public.productName", adapter.QuoteIdentifier("public.productName"))
+	require.Equal(t, `public."order"`, adapter.QuoteIdentifier("public.order"))
+	require.Equal(t, `"case"`, adapter.QuoteIdentifier("case"))
+	require.Equal(t, `"End"`, adapter.QuoteIdentifier("End"))
+	require.Equal(t, `"order-items"`, adapter.QuoteIdentifier("order-items"))
+}
@@ -0,0 +1,150 @@
+package sqlseeder
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvReader reads CSV records one at a time using the given separator and quote characters.
+// Quoted fields may contain separators, line breaks and doubled quote characters, a closing quote
+// must end the field. Empty lines are skipped (a line holding an empty quoted field is a record)
+// and both "\n" and "\r\n" line endings are accepted.
+type csvReader struct {
+	reader    *bufio.Reader
+	separator rune
+	quote     rune
+	line      int // 1-based line of the next rune
+}
+
+func newCSVReader(reader io.Reader, separator rune, quote rune) (*csvReader, error) {
+	if separator == quote {
+		return nil, fmt.Errorf("separator and quote must be different characters")
+	}
+	return &csvReader{reader: bufio.NewReader(reader), separator: separator, quote: quote, line: 1}, nil
+}
+
+// skipBOM drops a leading UTF-8 byte order mark.
+func (r *csvReader) skipBOM() error {
+	char, _, err := r.reader.ReadRune()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if char != '\uFEFF' {
+		return r.reader.UnreadRune()
+	}
+	return nil
+}
+
+// Read returns the next record and the 1-based line it starts on, io.EOF after the last record.
+func (r *csvReader) Read() ([]string, int, error) {
+	for {
+		record, line, blank, err := r.readRecord()
+		if err != nil {
+			return nil, 0, err
+		}
+		if !blank {
+			return record, line, nil
+		}
+	}
+}
+
+// peek reports whether the next rune is want and consumes it when it is.
+func (r *csvReader) peek(want rune) (bool, error) {
+	char, _, err := r.reader.ReadRune()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if char == want {
+		return true, nil
+	}
+	return false, r.reader.UnreadRune()
+}
+
+// readRecord reads the fields up to the next line break outside of quotes, blank reports an empty
+// line without quoted field.
+func (r *csvReader) readRecord() (record []string, line int, blank bool, err error) {
+	var (
+		field     strings.Builder
+		read      bool // a rune of the record was read
+		quoted    bool // inside a quoted field
+		closed    bool // right after the closing quote of a field
+		anyQuoted bool // the record has a quoted field
+	)
+	line = r.line
+	for {
+		char, _, err := r.reader.ReadRune()
+		if err == io.EOF {
+			if !read {
+				return nil, 0, false, io.EOF
+			}
+			if quoted {
+				return nil, 0, false, fmt.Errorf("line %d: unterminated quoted field", r.line)
+			}
+			break
+		}
+		if err != nil {
+			return nil, 0, false, err
+		}
+		read = true
+		if quoted {
+			if char == r.quote {
+				doubled, err := r.peek(r.quote)
+				if err != nil {
+					return nil, 0, false, err
+				}
+				if doubled {
+					field.WriteRune(r.quote)
+					continue
+				}
+				quoted = false
+				closed = true
+				continue
+			}
+			if char == '\n' {
+				r.line++
+			}
+			field.WriteRune(char)
+			continue
+		}
+		if char == '\r' {
+			lineBreak, err := r.peek('\n')
+			if err != nil {
+				return nil, 0, false, err
+			}
+			if lineBreak {
+				char = '\n'
+			}
+		}
+		if closed && char != r.separator && char != '\n' {
+			return nil, 0, false, fmt.Errorf("line %d: unexpected text after the closing quote of a field", r.line)
+		}
+		if char == '\n' {
+			r.line++
+			break
+		}
+		switch char {
+		case r.quote:
+			if field.Len() != 0 {
+				return nil, 0, false, fmt.Errorf("line %d: unexpected quote in unquoted field", r.line)
+			}
+			quoted = true
+			anyQuoted = true
+		case r.separator:
+			record = append(record, field.String())
+			field.Reset()
+			closed = false
+		default:
+			field.WriteRune(char)
+		}
+	}
+	record = append(record, field.String())
+	return record, line, len(record) == 1 && record[0] == "" && !anyQuoted, nil
+}
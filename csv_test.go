@@ -0,0 +1,76 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVLoader_Load(t *testing.T) {
+	content := "\uFEFF Product Name ,Category,Notes\r\n" +
+		"Laptop,Electronics,\"fast, light\"\r\n" +
+		"\r\n" +
+		"\"Kids \"\"Toys\"\"\",Toys,\"multi\nline\"\r\n"
+	loader := CSVLoader{
+		Content:       *bytes.NewBufferString(content),
+		ColumnsMapper: map[string]string{"category": "category_id**categories**category_name"},
+	}
+
+	data, err := loader.Load()
+	require.NoError(t, err)
+
+	expected := []map[string]interface{}{
+		{
+			"product name":                           "Laptop",
+			"category_id**categories**category_name": "Electronics",
+			"notes":                                  "fast, light",
+		},
+		{
+			"product name":                           "Kids \"Toys\"",
+			"category_id**categories**category_name": "Toys",
+			"notes":                                  "multi\nline",
+		},
+	}
+	require.Equal(t, expected, data)
+}
+
+func TestCSVLoader_LoadSeparatorAndQuote(t *testing.T) {
+	loader := CSVLoader{
+		Content:   *bytes.NewBufferString("name;tags\n'Laptop';'a;b|''c'''\n"),
+		Separator: ';',
+		Quote:     '\'',
+	}
+
+	data, err := loader.Load()
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{"name": "Laptop", "tags": "a;b|'c'"}}, data)
+}
+
+func TestCSVLoader_LoadRaggedRows(t *testing.T) {
+	content := "id,name,notes\n1,Laptop\n2,Phone,new,extra\n"
+
+	_, err := CSVLoader{Content: *bytes.NewBufferString(content)}.Load()
+	require.EqualError(t, err, "csv row 2 has 2 fields, expected 3")
+
+	// rows are reported at the line they start on, after blank lines and multiline fields
+	_, err = CSVLoader{Content: *bytes.NewBufferString("id,name,notes\n\n1,Laptop,\"multi\nline\"\n2,Phone\n")}.Load()
+	require.EqualError(t, err, "csv row 5 has 2 fields, expected 3")
+
+	data, err := CSVLoader{Content: *bytes.NewBufferString(content), AllowRaggedRows: true}.Load()
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{
+		{"id": "1", "name": "Laptop", "notes": ""},
+		{"id": "2", "name": "Phone", "notes": "new"},
+	}, data)
+}
+
+func TestCSVLoader_LoadQuotedFields(t *testing.T) {
+	_, err := CSVLoader{Content: *bytes.NewBufferString("name,notes\n\"abc\"def,x\n")}.Load()
+	require.EqualError(t, err, "failed to parse CSV: line 2: unexpected text after the closing quote of a field")
+
+	// a line holding an empty quoted field is a record, an empty line is not
+	data, err := CSVLoader{Content: *bytes.NewBufferString("name\n\"\"\n\nLaptop\n\"\"")}.Load()
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{"name": ""}, {"name": "Laptop"}, {"name": ""}}, data)
+}
@@ -0,0 +1,250 @@
+package sqlseeder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SeedAll loads every config and generates one script where each table is seeded after the tables
+// referenced by its one-to-many and many-to-many columns.
+// References to tables that are not part of configs are assumed to exist already and
+// self references are ignored, a dependency cycle is reported as an error.
+func (s *Seeder) SeedAll(configs []SeederConfig) (string, error) {
+	configs, datasets, err := s.loadOrdered(configs)
+	if err != nil {
+		return "", err
+	}
+
+	seeded := s.newSeededRows(configs, datasets)
+	scripts := make([]string, 0, len(configs))
+	for i, config := range configs {
+		script, err := s.seedData(config, datasets[i], seeded)
+		if err != nil {
+			return "", fmt.Errorf("failed to seed %s: %w", configName(config), err)
+		}
+		scripts = append(scripts, script)
+	}
+	return strings.Join(scripts, "\n"), nil
+}
+
+// loadOrdered loads every config and returns the configs with their datasets in dependency order.
+func (s *Seeder) loadOrdered(configs []SeederConfig) ([]SeederConfig, []*Dataset, error) {
+	datasets := make([]*Dataset, len(configs))
+	rows := make([][]map[string]interface{}, len(configs))
+	for i, config := range configs {
+		dataset, err := loadDataset(config.Loader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load %s: %w", configName(config), err)
+		}
+		datasets[i] = dataset
+		rows[i] = dataset.Rows
+	}
+
+	order, err := s.SortConfigs(configs, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	orderedConfigs := make([]SeederConfig, len(order))
+	orderedDatasets := make([]*Dataset, len(order))
+	for i, index := range order {
+		orderedConfigs[i] = configs[index]
+		orderedDatasets[i] = datasets[index]
+	}
+	return orderedConfigs, orderedDatasets, nil
+}
+
+// SortConfigs returns the indexes of configs in dependency order.
+// Configs without dependencies between them keep their original order.
+func (s *Seeder) SortConfigs(configs []SeederConfig, datasets [][]map[string]interface{}) ([]int, error) {
+	// map every table seeded by the configs (including many-to-many join tables) to its config
+	byFullName := make(map[string]int)
+	byTableName := make(map[string][]int)
+	register := func(fullName string, index int) {
+		if _, ok := byFullName[fullName]; ok {
+			return
+		}
+		byFullName[fullName] = index
+		parts := strings.Split(fullName, ".")
+		tableName := parts[len(parts)-1]
+		byTableName[tableName] = append(byTableName[tableName], index)
+	}
+	references := make([][]string, len(configs))
+	for i, config := range configs {
+		if config.FunctionName == "" {
+			register(s.Adapter.GetFullTableName(config.SchemaName, config.TableName), i)
+		}
+		tableReferences, joinTables, err := s.tableReferences(config, datasets[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read references of %s: %w", configName(config), err)
+		}
+		for _, joinTable := range joinTables {
+			register(joinTable, i)
+		}
+		references[i] = tableReferences
+	}
+
+	dependencies := make([]map[int]bool, len(configs))
+	dependents := make([][]int, len(configs))
+	for i, tableReferences := range references {
+		dependencies[i] = make(map[int]bool)
+		for _, reference := range tableReferences {
+			dependency, ok := byFullName[reference]
+			if !ok {
+				parts := strings.Split(reference, ".")
+				candidates := byTableName[parts[len(parts)-1]]
+				if len(candidates) != 1 {
+					// not seeded in this run (or ambiguous without a schema)
+					continue
+				}
+				dependency = candidates[0]
+			}
+			if dependency == i || dependencies[i][dependency] {
+				continue
+			}
+			dependencies[i][dependency] = true
+			dependents[dependency] = append(dependents[dependency], i)
+		}
+	}
+
+	// Kahn's algorithm always picking the lowest ready index to keep the input order stable
+	remaining := make([]int, len(configs))
+	ready := []int{}
+	for i := range configs {
+		remaining[i] = len(dependencies[i])
+		if remaining[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	order := make([]int, 0, len(configs))
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		current := ready[0]
+		ready = ready[1:]
+		order = append(order, current)
+		for _, dependent := range dependents[current] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if len(order) != len(configs) {
+		return nil, fmt.Errorf("dependency cycle between tables: %s", strings.Join(findCycle(configs, dependencies, remaining), " -> "))
+	}
+	return order, nil
+}
+
+// tableReferences returns the tables looked up by the columns of a config and
+// the many-to-many join tables and child tables it seeds, including those of the child rows.
+func (s *Seeder) tableReferences(config SeederConfig, data []map[string]interface{}) ([]string, []string, error) {
+	columns := make(map[string]bool)
+	for _, row := range data {
+		for column := range row {
+			columns[column] = true
+		}
+	}
+	sortedColumns := make([]string, 0, len(columns))
+	for column := range columns {
+		sortedColumns = append(sortedColumns, column)
+	}
+	sort.Strings(sortedColumns)
+
+	var references, joinTables []string
+	for _, column := range sortedColumns {
+		if s.Adapter.IsOneToMany(column) {
+			relation, err := s.Adapter.ParseOneToMany(column, config.TableName)
+			if err != nil {
+				return nil, nil, err
+			}
+			references = append(references, relation.Table)
+			continue
+		}
+		if s.Adapter.IsManyToMany(column) {
+			relation, err := s.Adapter.ParseManyToMany(column, config.SchemaName, config.TableName)
+			if err != nil {
+				return nil, nil, err
+			}
+			references = append(references, relation.SecondTable)
+			joinTables = append(joinTables, relation.Table)
+			continue
+		}
+		if s.Adapter.IsChildRows(column) {
+			relation, err := s.Adapter.ParseChildRows(column, config.SchemaName, config.TableName)
+			if err != nil {
+				return nil, nil, err
+			}
+			childReferences, childTables, err := s.tableReferences(SeederConfig{SchemaName: relation.Schema, TableName: relation.Table}, childRowsOf(data, column))
+			if err != nil {
+				return nil, nil, err
+			}
+			references = append(references, childReferences...)
+			joinTables = append(joinTables, s.Adapter.GetFullTableName(relation.Schema, relation.Table))
+			joinTables = append(joinTables, childTables...)
+		}
+	}
+	return references, joinTables, nil
+}
+
+// childRowsOf returns the child objects of a child rows column of every row.
+func childRowsOf(data []map[string]interface{}, column string) []map[string]interface{} {
+	var children []map[string]interface{}
+	for _, row := range data {
+		switch items := row[column].(type) {
+		case ChildRows:
+			children = append(children, items.Rows...)
+		case []interface{}:
+			for _, item := range items {
+				if child, ok := item.(map[string]interface{}); ok {
+					children = append(children, child)
+				}
+			}
+		}
+	}
+	return children
+}
+
+// findCycle walks the unresolved dependencies until a config repeats and returns the table names of the cycle.
+func findCycle(configs []SeederConfig, dependencies []map[int]bool, remaining []int) []string {
+	start := -1
+	for i := range remaining {
+		if remaining[i] > 0 {
+			start = i
+			break
+		}
+	}
+	visitedAt := make(map[int]int)
+	path := []int{}
+	current := start
+	for {
+		if at, ok := visitedAt[current]; ok {
+			path = append(path[at:], current)
+			break
+		}
+		visitedAt[current] = len(path)
+		path = append(path, current)
+		next := -1
+		for dependency := range dependencies[current] {
+			if remaining[dependency] > 0 && (next == -1 || dependency < next) {
+				next = dependency
+			}
+		}
+		current = next
+	}
+	names := make([]string, len(path))
+	for i, index := range path {
+		names[i] = configName(configs[index])
+	}
+	return names
+}
+
+// configName returns the name used to refer to a config in errors.
+func configName(config SeederConfig) string {
+	if config.FunctionName != "" {
+		return config.FunctionName
+	}
+	if config.SchemaName == "" {
+		return config.TableName
+	}
+	return fmt.Sprintf("%s.%s", config.SchemaName, config.TableName)
+}
@@ -0,0 +1,65 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func jsonConfig(tableName string, content string) SeederConfig {
+	return SeederConfig{
+		Loader:     JsonLoader{Content: *bytes.NewBufferString(content)},
+		SchemaName: "public",
+		TableName:  tableName,
+	}
+}
+
+func TestSeeder_SeedAll(t *testing.T) {
+	configs := []SeederConfig{
+		jsonConfig("products", `[{"product_name": "Laptop", "category_id**categories**category_name": "Electronics", "tag_id***product_tags***tags***tag_name***product_name": "new"}]`),
+		jsonConfig("tags", `[{"tag_name": "new"}]`),
+		jsonConfig("categories", `[{"category_name": "Electronics", "parent_id**categories**category_name": ""}]`),
+		jsonConfig("brands", `[{"brand_name": "Acme"}]`),
+	}
+
+	result, err := seeder.SeedAll(configs)
+	require.NoError(t, err)
+
+	tags := strings.Index(result, "INSERT INTO public.tags ")
+	categories := strings.Index(result, "INSERT INTO public.categories ")
+	brands := strings.Index(result, "INSERT INTO public.brands ")
+	products := strings.Index(result, "INSERT INTO public.products ")
+	productTags := strings.Index(result, "INSERT INTO product_tags ")
+	require.True(t, tags >= 0 && categories >= 0 && brands >= 0 && products >= 0 && productTags >= 0, result)
+	require.True(t, tags < categories && categories < products && products < brands && products < productTags, result)
+}
+
+func TestSeeder_SeedAllChildRows(t *testing.T) {
+	configs := []SeederConfig{
+		jsonConfig("orders", `[{"order_number": "A1", "order_lines>order_number": [{"product_id**products**sku": "X", "qty": 2}]}]`),
+		jsonConfig("line_discounts", `[{"amount": 5, "line_id**order_lines**line_code": "A1-1"}]`),
+		jsonConfig("products", `[{"sku": "X"}]`),
+	}
+
+	result, err := seeder.SeedAll(configs)
+	require.NoError(t, err)
+
+	products := strings.Index(result, "INSERT INTO public.products ")
+	orders := strings.Index(result, "INSERT INTO public.orders ")
+	lines := strings.Index(result, "INSERT INTO public.order_lines ")
+	discounts := strings.Index(result, "INSERT INTO public.line_discounts ")
+	require.True(t, products >= 0 && orders >= 0 && lines >= 0 && discounts >= 0, result)
+	require.True(t, products < orders && orders < lines && lines < discounts, result)
+}
+
+func TestSeeder_SeedAllCycle(t *testing.T) {
+	configs := []SeederConfig{
+		jsonConfig("users", `[{"email": "a@b.c", "team_id**teams**team_name": "core"}]`),
+		jsonConfig("teams", `[{"team_name": "core", "owner_id**users**email": "a@b.c"}]`),
+	}
+
+	_, err := seeder.SeedAll(configs)
+	require.EqualError(t, err, "dependency cycle between tables: public.users -> public.teams -> public.users")
+}
@@ -0,0 +1,534 @@
+package sqlseeder
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect controls the database specific parts of the generated SQL:
+// identifier quoting, literals and the statement wrapped around the VALUES rows.
+type Dialect interface {
+	// Name returns the dialect name (postgres, mysql, sqlite, sqlserver).
+	Name() string
+
+	// QuoteIdentifier quotes a single identifier part (e.g. a column or a table without schema).
+	QuoteIdentifier(identifier string) string
+
+	// StringLiteral renders a string as a quoted and escaped literal.
+	StringLiteral(value string) string
+
+	// BoolLiteral renders a boolean literal.
+	BoolLiteral(value bool) string
+
+	// ArrayLiteral renders a list of typed values as an array literal.
+	ArrayLiteral(values []interface{}) string
+
+	// JSONLiteral renders an encoded JSON document as a literal.
+	JSONLiteral(value string) string
+
+	// VectorLiteral renders an embedding vector as a literal.
+	VectorLiteral(vector []float32) string
+
+	// InsertHeader renders everything that comes before the VALUES rows.
+	InsertHeader(clause InsertClause) string
+
+	// InsertFooter renders everything that comes after the VALUES rows including the terminating semicolon.
+	InsertFooter(clause InsertClause) string
+
+	// FunctionCall renders a call to a database function receiving the data as a JSON document,
+	// argument is the already rendered JSON literal or placeholder.
+	FunctionCall(functionName string, argument string) string
+
+	// Placeholder renders the placeholder of the n-th (1-based) argument of a statement.
+	Placeholder(index int) string
+
+	// ArrayArgument converts an array to a value that can be bound to a placeholder.
+	ArrayArgument(values []interface{}) interface{}
+
+	// SupportsDefaultValues reports whether DEFAULT can be written in place of a value in the VALUES rows,
+	// statements are split so that defaulted columns are left out otherwise.
+	SupportsDefaultValues() bool
+}
+
+// LookupGuardDialect is implemented by dialects that can check the lookups of a statement in a
+// generated script, the guard runs right before the statement and fails when a value is not found.
+type LookupGuardDialect interface {
+	// LookupGuard renders the statements raising an error when one of the checks returns no row,
+	// table names the statement in the error message.
+	LookupGuard(table string, checks []LookupCheck) string
+}
+
+// InsertClause holds the already quoted names a dialect needs to wrap the VALUES rows of a statement.
+type InsertClause struct {
+	Table              string
+	Columns            []string
+	ConflictAction     ConflictAction
+	ConflictTarget     []string
+	ConflictConstraint string
+	UpdateColumns      []string
+	Select             bool // the rows are SELECT queries joined by UNION ALL instead of VALUES rows
+	// MatchColumns identify an existing row for dialects that match rows themselves instead of relying on
+	// the unique keys of the table: the conflict target, the statement Key or the inserted primary key.
+	MatchColumns []string
+}
+
+// valuesKeyword returns the keyword that comes before the rows of a statement.
+func valuesKeyword(clause InsertClause) string {
+	if clause.Select {
+		return ""
+	}
+	return " VALUES"
+}
+
+// updateAssignments renders "column = <source>" pairs for the update columns of an upsert.
+func updateAssignments(clause InsertClause, source func(column string) string) string {
+	assignments := make([]string, len(clause.UpdateColumns))
+	for i, column := range clause.UpdateColumns {
+		assignments[i] = fmt.Sprintf("%s = %s", column, source(column))
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// isNullToken reports whether a cell text stands for NULL.
+func isNullToken(value string) bool {
+	return value == "" || value == "NULL" || value == "null"
+}
+
+// isEmptyToken reports whether a cell text is the EMPTY token standing for the empty value of a column.
+func isEmptyToken(value string) bool {
+	return value == "EMPTY" || value == "empty"
+}
+
+// FormatLiteral renders a typed cell value as an SQL literal of the given dialect.
+// Empty strings and the NULL tokens are rendered as NULL.
+func FormatLiteral(dialect Dialect, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case RawSQL:
+		return string(v)
+	case DefaultValue, OmittedValue:
+		return "DEFAULT"
+	case SQLExpr:
+		return v.Render(func(arg interface{}) string {
+			return FormatLiteral(dialect, arg)
+		})
+	case string:
+		if isNullToken(v) {
+			return "NULL"
+		}
+		return dialect.StringLiteral(v)
+	case bool:
+		return dialect.BoolLiteral(v)
+	case json.Number:
+		return v.String()
+	case float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "NULL"
+		}
+		return dialect.JSONLiteral(string(encoded))
+	case []interface{}:
+		return dialect.ArrayLiteral(v)
+	default:
+		return FormatLiteral(dialect, fmt.Sprint(v))
+	}
+}
+
+// vectorText renders a vector using the "[0.1,0.2]" text format shared by pgvector and most vector types.
+func vectorText(vector []float32) string {
+	items := make([]string, len(vector))
+	for i, item := range vector {
+		items[i] = strconv.FormatFloat(float64(item), 'f', -1, 32)
+	}
+	return fmt.Sprintf("[%s]", strings.Join(items, ","))
+}
+
+// bindArgument converts a typed cell value to a value accepted by database/sql drivers.
+// Objects are bound as JSON documents and arrays as the dialect ArrayArgument.
+func bindArgument(dialect Dialect, value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if integer, err := v.Int64(); err == nil {
+			return integer
+		}
+		return v.String()
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		return string(encoded)
+	case []interface{}:
+		return dialect.ArrayArgument(v)
+	case string, bool, float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// jsonArrayArgument encodes an array as a JSON document for dialects without native arrays.
+func jsonArrayArgument(values []interface{}) interface{} {
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	return string(encoded)
+}
+
+// jsonArrayLiteral renders an array as a JSON document for dialects without native arrays.
+func jsonArrayLiteral(dialect Dialect, values []interface{}) string {
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "NULL"
+	}
+	return dialect.JSONLiteral(string(encoded))
+}
+
+// PostgresDialect generates PostgreSQL statements (the default dialect).
+type PostgresDialect struct{}
+
+func (d PostgresDialect) Name() string { return "postgres" }
+
+func (d PostgresDialect) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(identifier, `"`, `""`))
+}
+
+func (d PostgresDialect) StringLiteral(value string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", "''"))
+}
+
+func (d PostgresDialect) BoolLiteral(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (d PostgresDialect) ArrayLiteral(values []interface{}) string {
+	if len(values) == 0 {
+		return "'{}'"
+	}
+	items := make([]string, len(values))
+	for i, item := range values {
+		items[i] = FormatLiteral(d, item)
+	}
+	return fmt.Sprintf("ARRAY[%s]", strings.Join(items, ", "))
+}
+
+func (d PostgresDialect) JSONLiteral(value string) string {
+	return fmt.Sprintf("%s::jsonb", d.StringLiteral(value))
+}
+
+func (d PostgresDialect) VectorLiteral(vector []float32) string {
+	return fmt.Sprintf("%s::vector", d.StringLiteral(vectorText(vector)))
+}
+
+func (d PostgresDialect) InsertHeader(clause InsertClause) string {
+	return fmt.Sprintf("INSERT INTO %s (%s)%s", clause.Table, strings.Join(clause.Columns, ", "), valuesKeyword(clause))
+}
+
+func (d PostgresDialect) InsertFooter(clause InsertClause) string {
+	if clause.ConflictAction == ConflictError {
+		return ";"
+	}
+	target := ""
+	if clause.ConflictConstraint != "" {
+		target = fmt.Sprintf(" ON CONSTRAINT %s", clause.ConflictConstraint)
+	} else if len(clause.ConflictTarget) > 0 {
+		target = fmt.Sprintf(" (%s)", strings.Join(clause.ConflictTarget, ", "))
+	}
+	if clause.ConflictAction == ConflictDoUpdate && len(clause.UpdateColumns) > 0 {
+		return fmt.Sprintf(" ON CONFLICT%s DO UPDATE SET %s;", target, updateAssignments(clause, func(column string) string {
+			return "EXCLUDED." + column
+		}))
+	}
+	return fmt.Sprintf(" ON CONFLICT%s DO NOTHING;", target)
+}
+
+// LookupGuard collects every unresolved value in a DO block and raises them at once.
+// The block body is written as a regular string literal so that lookup values can't end it.
+func (d PostgresDialect) LookupGuard(table string, checks []LookupCheck) string {
+	var body strings.Builder
+	body.WriteString("DECLARE missing TEXT[] := ARRAY[]::TEXT[];\nBEGIN\n")
+	for _, check := range checks {
+		fmt.Fprintf(&body, "  IF NOT EXISTS (%s) THEN missing := array_append(missing, %s); END IF;\n", check.SQL, d.StringLiteral(check.String()))
+	}
+	fmt.Fprintf(&body, "  IF cardinality(missing) > 0 THEN RAISE EXCEPTION 'unresolved lookups in %%: %%', %s, array_to_string(missing, ', '); END IF;\nEND", d.StringLiteral(table))
+	return fmt.Sprintf("DO %s;", d.StringLiteral(body.String()))
+}
+
+func (d PostgresDialect) FunctionCall(functionName string, argument string) string {
+	return fmt.Sprintf("SELECT %s(%s::JSONB);", functionName, argument)
+}
+
+func (d PostgresDialect) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
+// ArrayArgument renders the array text format ({"a","b"}) that PostgreSQL casts to the column array type.
+func (d PostgresDialect) ArrayArgument(values []interface{}) interface{} {
+	items := make([]string, len(values))
+	for i, item := range values {
+		switch v := item.(type) {
+		case nil:
+			items[i] = "NULL"
+		case []interface{}:
+			items[i] = d.ArrayArgument(v).(string)
+		default:
+			text := fmt.Sprint(bindArgument(d, v))
+			text = strings.ReplaceAll(strings.ReplaceAll(text, `\`, `\\`), `"`, `\"`)
+			items[i] = fmt.Sprintf(`"%s"`, text)
+		}
+	}
+	return fmt.Sprintf("{%s}", strings.Join(items, ","))
+}
+
+func (d PostgresDialect) SupportsDefaultValues() bool {
+	return true
+}
+
+// MySQLDialect generates MySQL / MariaDB statements.
+// Arrays are stored as JSON documents since MySQL has no array type.
+type MySQLDialect struct{}
+
+func (d MySQLDialect) Name() string { return "mysql" }
+
+func (d MySQLDialect) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(identifier, "`", "``"))
+}
+
+func (d MySQLDialect) StringLiteral(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(escaped, "'", "''"))
+}
+
+func (d MySQLDialect) BoolLiteral(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (d MySQLDialect) ArrayLiteral(values []interface{}) string {
+	return jsonArrayLiteral(d, values)
+}
+
+func (d MySQLDialect) JSONLiteral(value string) string {
+	return d.StringLiteral(value)
+}
+
+func (d MySQLDialect) VectorLiteral(vector []float32) string {
+	return d.StringLiteral(vectorText(vector))
+}
+
+func (d MySQLDialect) InsertHeader(clause InsertClause) string {
+	insert := "INSERT"
+	if clause.ConflictAction == ConflictDoNothing || len(clause.UpdateColumns) == 0 && clause.ConflictAction == ConflictDoUpdate {
+		insert = "INSERT IGNORE"
+	}
+	return fmt.Sprintf("%s INTO %s (%s)%s", insert, clause.Table, strings.Join(clause.Columns, ", "), valuesKeyword(clause))
+}
+
+// InsertFooter relies on the table unique keys, the conflict target is only used to pick the update columns.
+func (d MySQLDialect) InsertFooter(clause InsertClause) string {
+	if clause.ConflictAction == ConflictDoUpdate && len(clause.UpdateColumns) > 0 {
+		return fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s;", updateAssignments(clause, func(column string) string {
+			return fmt.Sprintf("VALUES(%s)", column)
+		}))
+	}
+	return ";"
+}
+
+func (d MySQLDialect) FunctionCall(functionName string, argument string) string {
+	return fmt.Sprintf("CALL %s(%s);", functionName, argument)
+}
+
+func (d MySQLDialect) Placeholder(index int) string {
+	return "?"
+}
+
+func (d MySQLDialect) ArrayArgument(values []interface{}) interface{} {
+	return jsonArrayArgument(values)
+}
+
+func (d MySQLDialect) SupportsDefaultValues() bool {
+	return true
+}
+
+// SQLiteDialect generates SQLite statements.
+// Arrays are stored as JSON documents and booleans as 1 / 0.
+type SQLiteDialect struct{}
+
+func (d SQLiteDialect) Name() string { return "sqlite" }
+
+func (d SQLiteDialect) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(identifier, `"`, `""`))
+}
+
+func (d SQLiteDialect) StringLiteral(value string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", "''"))
+}
+
+func (d SQLiteDialect) BoolLiteral(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+func (d SQLiteDialect) ArrayLiteral(values []interface{}) string {
+	return jsonArrayLiteral(d, values)
+}
+
+func (d SQLiteDialect) JSONLiteral(value string) string {
+	return d.StringLiteral(value)
+}
+
+func (d SQLiteDialect) VectorLiteral(vector []float32) string {
+	return d.StringLiteral(vectorText(vector))
+}
+
+func (d SQLiteDialect) InsertHeader(clause InsertClause) string {
+	insert := "INSERT"
+	if clause.ConflictAction == ConflictDoNothing {
+		insert = "INSERT OR IGNORE"
+	}
+	return fmt.Sprintf("%s INTO %s (%s)%s", insert, clause.Table, strings.Join(clause.Columns, ", "), valuesKeyword(clause))
+}
+
+func (d SQLiteDialect) InsertFooter(clause InsertClause) string {
+	if clause.ConflictAction != ConflictDoUpdate {
+		return ";"
+	}
+	// SQLite can't tell an upsert clause from a join constraint after a SELECT without a WHERE clause
+	where := ""
+	if clause.Select {
+		where = " WHERE true"
+	}
+	if len(clause.UpdateColumns) == 0 {
+		return fmt.Sprintf("%s ON CONFLICT (%s) DO NOTHING;", where, strings.Join(clause.ConflictTarget, ", "))
+	}
+	return fmt.Sprintf("%s ON CONFLICT (%s) DO UPDATE SET %s;", where, strings.Join(clause.ConflictTarget, ", "), updateAssignments(clause, func(column string) string {
+		return "excluded." + column
+	}))
+}
+
+func (d SQLiteDialect) FunctionCall(functionName string, argument string) string {
+	return fmt.Sprintf("SELECT %s(%s);", functionName, argument)
+}
+
+func (d SQLiteDialect) Placeholder(index int) string {
+	return "?"
+}
+
+func (d SQLiteDialect) ArrayArgument(values []interface{}) interface{} {
+	return jsonArrayArgument(values)
+}
+
+// SQLite has no DEFAULT keyword in VALUES rows.
+func (d SQLiteDialect) SupportsDefaultValues() bool {
+	return false
+}
+
+// SQLServerDialect generates SQL Server statements.
+// Conflicts are handled with a MERGE matching rows on the MatchColumns of the statement (the conflict
+// target, the statement Key or the inserted primary key), arrays are stored as JSON documents and booleans as 1 / 0.
+// Statements without MatchColumns (e.g. tables generating their IDENTITY key) are written as a plain INSERT.
+type SQLServerDialect struct{}
+
+func (d SQLServerDialect) Name() string { return "sqlserver" }
+
+func (d SQLServerDialect) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf("[%s]", strings.ReplaceAll(identifier, "]", "]]"))
+}
+
+func (d SQLServerDialect) StringLiteral(value string) string {
+	return fmt.Sprintf("N'%s'", strings.ReplaceAll(value, "'", "''"))
+}
+
+func (d SQLServerDialect) BoolLiteral(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+func (d SQLServerDialect) ArrayLiteral(values []interface{}) string {
+	return jsonArrayLiteral(d, values)
+}
+
+func (d SQLServerDialect) JSONLiteral(value string) string {
+	return d.StringLiteral(value)
+}
+
+func (d SQLServerDialect) VectorLiteral(vector []float32) string {
+	return d.StringLiteral(vectorText(vector))
+}
+
+func (d SQLServerDialect) InsertHeader(clause InsertClause) string {
+	if !d.merges(clause) {
+		return fmt.Sprintf("INSERT INTO %s (%s)%s", clause.Table, strings.Join(clause.Columns, ", "), valuesKeyword(clause))
+	}
+	return fmt.Sprintf("MERGE INTO %s AS target USING (%s", clause.Table, strings.TrimSpace(valuesKeyword(clause)))
+}
+
+func (d SQLServerDialect) InsertFooter(clause InsertClause) string {
+	if !d.merges(clause) {
+		return ";"
+	}
+	conditions := make([]string, len(clause.MatchColumns))
+	for i, column := range clause.MatchColumns {
+		conditions[i] = fmt.Sprintf("target.%s = source.%s", column, column)
+	}
+	sourceColumns := make([]string, len(clause.Columns))
+	for i, column := range clause.Columns {
+		sourceColumns[i] = fmt.Sprintf("source.%s", column)
+	}
+	update := ""
+	if clause.ConflictAction == ConflictDoUpdate && len(clause.UpdateColumns) > 0 {
+		update = fmt.Sprintf(" WHEN MATCHED THEN UPDATE SET %s", updateAssignments(clause, func(column string) string {
+			return "source." + column
+		}))
+	}
+	columns := strings.Join(clause.Columns, ", ")
+	return fmt.Sprintf(") AS source (%s) ON %s%s WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);",
+		columns, strings.Join(conditions, " AND "), update, columns, strings.Join(sourceColumns, ", "))
+}
+
+// merges reports whether the statement is written as a MERGE, it needs columns to match existing rows on.
+func (d SQLServerDialect) merges(clause InsertClause) bool {
+	return clause.ConflictAction != ConflictError && len(clause.MatchColumns) > 0
+}
+
+// LookupGuard throws on the first unresolved value.
+func (d SQLServerDialect) LookupGuard(table string, checks []LookupCheck) string {
+	guards := make([]string, len(checks))
+	for i, check := range checks {
+		message := fmt.Sprintf("unresolved lookups in %s: %s", table, check.String())
+		guards[i] = fmt.Sprintf("IF NOT EXISTS (%s) THROW 50000, %s, 1;", check.SQL, d.StringLiteral(message))
+	}
+	return strings.Join(guards, "\n")
+}
+
+func (d SQLServerDialect) FunctionCall(functionName string, argument string) string {
+	return fmt.Sprintf("EXEC %s %s;", functionName, argument)
+}
+
+func (d SQLServerDialect) Placeholder(index int) string {
+	return fmt.Sprintf("@p%d", index)
+}
+
+func (d SQLServerDialect) ArrayArgument(values []interface{}) interface{} {
+	return jsonArrayArgument(values)
+}
+
+// The VALUES rows of MERGE are a table value constructor where DEFAULT is not allowed.
+func (d SQLServerDialect) SupportsDefaultValues() bool {
+	return false
+}
@@ -0,0 +1,258 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_Generate(t *testing.T) {
+	data := SQLData{
+		Statements: []SQLStatement{
+			{
+				Schema:  "shop",
+				Table:   "order",
+				Columns: []string{"order_id", "name", "active", "tags[]"},
+				Rows: []map[string]interface{}{
+					{"order_id": json.Number("7"), "name": "Kid's", "active": true, "tags[]": []interface{}{"a", "b"}},
+				},
+			},
+		},
+	}
+	testCases := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{PostgresDialect{}, `INSERT INTO shop."order" (order_id, name, active, tags) VALUES ( 7, 'Kid''s', TRUE, ARRAY['a', 'b'] ) ON CONFLICT DO NOTHING;`},
+		{MySQLDialect{}, "INSERT IGNORE INTO shop.`order` (order_id, name, active, tags) VALUES ( 7, 'Kid''s', TRUE, '[\"a\",\"b\"]' );"},
+		{SQLiteDialect{}, `INSERT OR IGNORE INTO shop."order" (order_id, name, active, tags) VALUES ( 7, 'Kid''s', 1, '["a","b"]' );`},
+		{SQLServerDialect{}, `MERGE INTO shop.[order] AS target USING (VALUES ( 7, N'Kid''s', 1, N'["a","b"]' )) AS source (order_id, name, active, tags) ON target.order_id = source.order_id WHEN NOT MATCHED THEN INSERT (order_id, name, active, tags) VALUES (source.order_id, source.name, source.active, source.tags);`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.dialect.Name(), func(t *testing.T) {
+			dialectSeeder := NewSeeder(SeederConfigInit{Dialect: tc.dialect})
+			result, err := dialectSeeder.GetGenerator().Generate(data)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, strings.Join(strings.Fields(result), " "))
+		})
+	}
+}
+
+func TestDialect_FunctionCall(t *testing.T) {
+	require.Equal(t, "SELECT seed_products('{\"name\":\"it''s\"}'::JSONB);", PostgresDialect{}.FunctionCall("seed_products", PostgresDialect{}.StringLiteral(`{"name":"it's"}`)))
+	require.Equal(t, `CALL seed_products('{\\"a\\":1}');`, MySQLDialect{}.FunctionCall("seed_products", MySQLDialect{}.StringLiteral(`{\"a\":1}`)))
+	require.Equal(t, "EXEC seed_products N'[]';", SQLServerDialect{}.FunctionCall("seed_products", SQLServerDialect{}.StringLiteral("[]")))
+	require.Equal(t, "SELECT seed_products($1::JSONB);", PostgresDialect{}.FunctionCall("seed_products", PostgresDialect{}.Placeholder(1)))
+}
+
+func TestDialect_GenerateConflict(t *testing.T) {
+	stmt := SQLStatement{
+		Table:   "categories",
+		Columns: []string{"category_code", "category_name", "is_active"},
+		Rows: []map[string]interface{}{
+			{"category_code": "EL", "category_name": "Electronics", "is_active": true},
+		},
+	}
+	values := "( 'EL', 'Electronics', TRUE )"
+	testCases := []struct {
+		name     string
+		dialect  Dialect
+		conflict ConflictConfig
+		expected string
+	}{
+		{
+			name:     "postgres update all",
+			dialect:  PostgresDialect{},
+			conflict: ConflictConfig{Action: ConflictDoUpdate, Target: []string{"category_code"}},
+			expected: "INSERT INTO categories (category_code, category_name, is_active) VALUES " + values + " ON CONFLICT (category_code) DO UPDATE SET category_name = EXCLUDED.category_name, is_active = EXCLUDED.is_active;",
+		},
+		{
+			name:     "postgres constraint",
+			dialect:  PostgresDialect{},
+			conflict: ConflictConfig{Action: ConflictDoUpdate, Constraint: "categories_code_key", UpdateColumns: []string{"category_name"}},
+			expected: "INSERT INTO categories (category_code, category_name, is_active) VALUES " + values + " ON CONFLICT ON CONSTRAINT categories_code_key DO UPDATE SET category_name = EXCLUDED.category_name;",
+		},
+		{
+			name:     "postgres error",
+			dialect:  PostgresDialect{},
+			conflict: ConflictConfig{Action: ConflictError},
+			expected: "INSERT INTO categories (category_code, category_name, is_active) VALUES " + values + ";",
+		},
+		{
+			name:     "mysql update",
+			dialect:  MySQLDialect{},
+			conflict: ConflictConfig{Action: ConflictDoUpdate, Target: []string{"category_code"}, UpdateColumns: []string{"category_name"}},
+			expected: "INSERT INTO categories (category_code, category_name, is_active) VALUES " + values + " ON DUPLICATE KEY UPDATE category_name = VALUES(category_name);",
+		},
+		{
+			name:     "sqlite update",
+			dialect:  SQLiteDialect{},
+			conflict: ConflictConfig{Action: ConflictDoUpdate, Target: []string{"category_code"}, UpdateColumns: []string{"category_name"}},
+			expected: "INSERT INTO categories (category_code, category_name, is_active) VALUES ( 'EL', 'Electronics', 1 ) ON CONFLICT (category_code) DO UPDATE SET category_name = excluded.category_name;",
+		},
+		{
+			name:     "sqlserver update",
+			dialect:  SQLServerDialect{},
+			conflict: ConflictConfig{Action: ConflictDoUpdate, Target: []string{"category_code"}, UpdateColumns: []string{"category_name"}},
+			expected: "MERGE INTO categories AS target USING (VALUES ( N'EL', N'Electronics', 1 )) AS source (category_code, category_name, is_active) ON target.category_code = source.category_code WHEN MATCHED THEN UPDATE SET category_name = source.category_name WHEN NOT MATCHED THEN INSERT (category_code, category_name, is_active) VALUES (source.category_code, source.category_name, source.is_active);",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dialectSeeder := NewSeeder(SeederConfigInit{Dialect: tc.dialect})
+			stmt.Conflict = tc.conflict
+			result, err := dialectSeeder.GetGenerator().Generate(SQLData{Statements: []SQLStatement{stmt}})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, strings.Join(strings.Fields(result), " "))
+		})
+	}
+}
+
+func TestDialect_GenerateFrom(t *testing.T) {
+	stmt := SQLStatement{
+		Table:   "user_roles",
+		Columns: []string{"user_id", "role_id"},
+		Rows: []map[string]interface{}{
+			{"user_id": RawSQL("1"), "role_id": RawSQL("role_id")},
+			{"user_id": RawSQL("2"), "role_id": RawSQL("role_id")},
+		},
+		From: "roles",
+		Key:  []string{"user_id", "role_id"},
+	}
+	selects := "SELECT 1, role_id FROM roles UNION ALL SELECT 2, role_id FROM roles"
+	testCases := []struct {
+		dialect  Dialect
+		conflict ConflictConfig
+		expected string
+	}{
+		{PostgresDialect{}, ConflictConfig{}, "INSERT INTO user_roles (user_id, role_id) " + selects + " ON CONFLICT DO NOTHING;"},
+		{MySQLDialect{}, ConflictConfig{}, "INSERT IGNORE INTO user_roles (user_id, role_id) " + selects + ";"},
+		{SQLiteDialect{}, ConflictConfig{Action: ConflictDoUpdate, Target: []string{"user_id", "role_id"}}, "INSERT INTO user_roles (user_id, role_id) " + selects + " WHERE true ON CONFLICT (user_id, role_id) DO NOTHING;"},
+		{SQLServerDialect{}, ConflictConfig{}, "MERGE INTO user_roles AS target USING ( " + selects + ") AS source (user_id, role_id) ON target.user_id = source.user_id AND target.role_id = source.role_id WHEN NOT MATCHED THEN INSERT (user_id, role_id) VALUES (source.user_id, source.role_id);"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.dialect.Name(), func(t *testing.T) {
+			dialectSeeder := NewSeeder(SeederConfigInit{Dialect: tc.dialect})
+			stmt.Conflict = tc.conflict
+			result, err := dialectSeeder.GetGenerator().Generate(SQLData{Statements: []SQLStatement{stmt}})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, strings.Join(strings.Fields(result), " "))
+		})
+	}
+}
+
+func TestDialect_LookupGuardLocation(t *testing.T) {
+	content := newTestWorkbook(t, map[string][][]interface{}{
+		"products": {
+			{"product_name", "category_id**categories**category_name"},
+			{"Laptop", "Electronics"},
+			{},
+			{"Mouse", "Electonics"},
+		},
+	}, []string{"products"})
+	config := func() SeederConfig {
+		return SeederConfig{
+			Loader:     ExcelLoader{Content: *bytes.NewBuffer(content.Bytes()), SheetName: "products"},
+			SchemaName: "public",
+			TableName:  "products",
+		}
+	}
+
+	// the guards name the cell of the first row looking each value up
+	result, err := NewSeeder(SeederConfigInit{StrictLookups: true}).Seed(config())
+	require.NoError(t, err)
+	require.Contains(t, result, "array_append(missing, ''categories.category_name = ''''Electronics'''' (excel products!B2, column ''''category_id**categories**category_name'''')'')")
+	require.Contains(t, result, "array_append(missing, ''categories.category_name = ''''Electonics'''' (excel products!B4, column ''''category_id**categories**category_name'''')'')")
+
+	// later batches of SeedTo are located after the rows of the previous ones
+	var script bytes.Buffer
+	require.NoError(t, NewSeeder(SeederConfigInit{StrictLookups: true, MaxRowsPerStatement: 1}).SeedTo(&script, config()))
+	require.Contains(t, script.String(), "''''Electonics'''' (excel products!B4, column")
+
+	result, err = NewSeeder(SeederConfigInit{StrictLookups: true, Dialect: SQLServerDialect{}}).Seed(config())
+	require.NoError(t, err)
+	require.Contains(t, result, "THROW 50000, N'unresolved lookups in public.products: categories.category_name = ''Electonics'' (excel products!B4, column ''category_id**categories**category_name'')', 1;")
+}
+
+func TestDialect_LookupGuard(t *testing.T) {
+	data := SQLData{Statements: []SQLStatement{{
+		Schema:  "public",
+		Table:   "products",
+		Key:     []string{"product_name"},
+		Columns: []string{"product_name", "category_id**categories**category_name"},
+		Rows: []map[string]interface{}{
+			{"product_name": "Laptop", "category_id**categories**category_name": lookupExpr("category_id", "categories", "category_name", "Kids' Toys")},
+			{"product_name": "Mouse", "category_id**categories**category_name": lookupExpr("category_id", "categories", "category_name", "Kids' Toys")},
+		},
+	}}}
+
+	result, err := NewSeeder(SeederConfigInit{StrictLookups: true}).GetGenerator().Generate(data)
+	require.NoError(t, err)
+	require.Equal(t, "DO 'DECLARE missing TEXT[] := ARRAY[]::TEXT[];\n"+
+		"BEGIN\n"+
+		"  IF NOT EXISTS (SELECT 1 FROM categories WHERE category_name = ''Kids'''' Toys'') THEN missing := array_append(missing, ''categories.category_name = ''''Kids'''' Toys''''''); END IF;\n"+
+		"  IF cardinality(missing) > 0 THEN RAISE EXCEPTION ''unresolved lookups in %: %'', ''public.products'', array_to_string(missing, '', ''); END IF;\n"+
+		"END';", strings.Split(strings.TrimSpace(result), "\nINSERT")[0])
+
+	result, err = NewSeeder(SeederConfigInit{StrictLookups: true, Dialect: SQLServerDialect{}}).GetGenerator().Generate(data)
+	require.NoError(t, err)
+	require.Contains(t, result, "IF NOT EXISTS (SELECT 1 FROM categories WHERE category_name = N'Kids'' Toys') THROW 50000, N'unresolved lookups in public.products: categories.category_name = ''Kids'' Toys''', 1;\nMERGE INTO")
+
+	_, err = NewSeeder(SeederConfigInit{StrictLookups: true, Dialect: MySQLDialect{}}).GetGenerator().Generate(data)
+	require.ErrorContains(t, err, "the mysql dialect can't check lookups in generated SQL, run the seeds with Execute to check them against the database")
+
+	result, err = NewSeeder(SeederConfigInit{}).GetGenerator().Generate(data)
+	require.NoError(t, err)
+	require.NotContains(t, result, "DO 'DECLARE")
+}
+
+func TestDialect_GenerateSQLServerMatchColumns(t *testing.T) {
+	sqlServer := NewSeeder(SeederConfigInit{Dialect: SQLServerDialect{}}).GetGenerator()
+	// the existing category 1 has another name, the MERGE matches it on its key only so it is skipped
+	// instead of being inserted a second time, and NULL columns don't prevent the match
+	result, err := sqlServer.Generate(SQLData{Statements: []SQLStatement{{
+		Table:   "categories",
+		Columns: []string{"category_id", "category_name", "parent_id"},
+		Rows:    []map[string]interface{}{{"category_id": json.Number("1"), "category_name": "Renamed", "parent_id": nil}},
+	}}})
+	require.NoError(t, err)
+	require.Contains(t, strings.Join(strings.Fields(result), " "), "AS source (category_id, category_name, parent_id) ON target.category_id = source.category_id WHEN NOT MATCHED THEN INSERT")
+
+	noKey := SQLData{Statements: []SQLStatement{{
+		Table:   "categories",
+		Columns: []string{"category_name"},
+		Rows:    []map[string]interface{}{{"category_name": "Books"}},
+	}}}
+	// the IDENTITY key is generated by the database, nothing identifies an existing row so the rows are inserted
+	result, err = sqlServer.Generate(noKey)
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO categories (category_name) VALUES ( N'Books' );", strings.Join(strings.Fields(result), " "))
+	statements, err := sqlServer.GenerateParameterized(noKey)
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO categories (category_name) VALUES ( @p1 );", strings.Join(strings.Fields(statements[0].SQL), " "))
+
+	noKey.Statements[0].Conflict = ConflictConfig{Action: ConflictDoUpdate, Target: []string{"category_name"}}
+	result, err = sqlServer.Generate(noKey)
+	require.NoError(t, err)
+	require.Contains(t, result, "ON target.category_name = source.category_name")
+
+	noKey.Statements[0].Conflict = ConflictConfig{Action: ConflictError}
+	result, err = sqlServer.Generate(noKey)
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO categories (category_name) VALUES ( N'Books' );", strings.Join(strings.Fields(result), " "))
+}
+
+func TestDialect_Constructors(t *testing.T) {
+	adapter := NewAdapter("**", "***")
+	require.Equal(t, PostgresDialect{}, adapter.(*Adapter).Dialect)
+	require.Equal(t, PostgresDialect{}, NewGenerator(adapter, nil, "|", ",", "**", "***", nil).(*Generator).Dialect)
+
+	// the generator takes the dialect of the adapter
+	adapter = NewAdapter("**", "***", WithDialect(MySQLDialect{}))
+	require.Equal(t, MySQLDialect{}, adapter.(*Adapter).Dialect)
+	require.Equal(t, MySQLDialect{}, NewGenerator(adapter, nil, "|", ",", "**", "***", nil).(*Generator).Dialect)
+	require.Equal(t, SQLiteDialect{}, NewGeneratorWithConfig(adapter, GeneratorConfig{Dialect: SQLiteDialect{}}).(*Generator).Dialect)
+}
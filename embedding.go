@@ -0,0 +1,136 @@
+package sqlseeder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// defaultEmbedBatchSize is the number of texts embedded per EmbedBulk call when EmbedBatchSize is not set.
+const defaultEmbedBatchSize = 100
+
+// embedColumns replaces the values of the embedding columns (e.g. description_embedding~description)
+// with the vector literal of their source column text.
+// The values are replaced in copyRows copies of the rows.
+// Texts are embedded in batches of EmbedBatchSize (100 when unset) using EmbedBulk, or one by one using Embed when
+// EmbedBulk is not set. Rows with an empty source text get a NULL embedding.
+func (s *Seeder) embedColumns(ctx context.Context, data []map[string]interface{}) ([]map[string]interface{}, error) {
+	embeddingColumns := make(map[string]bool)
+	for _, row := range data {
+		for column := range row {
+			if s.Adapter.IsEmbeddingColumn(column) {
+				embeddingColumns[column] = true
+			}
+		}
+	}
+	if len(embeddingColumns) == 0 {
+		return data, nil
+	}
+	data = copyRows(data)
+	columns := make([]string, 0, len(embeddingColumns))
+	for column := range embeddingColumns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	batchSize := s.EmbedBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultEmbedBatchSize
+	}
+
+	for _, column := range columns {
+		relation, err := s.Adapter.ParseEmbedding(column)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			texts []string
+			rows  []map[string]interface{}
+		)
+		for _, row := range data {
+			text := s.sourceText(row, relation.SourceColumn)
+			if text == "" {
+				row[column] = nil
+				continue
+			}
+			texts = append(texts, text)
+			rows = append(rows, row)
+		}
+		for start := 0; start < len(texts); start += batchSize {
+			end := min(start+batchSize, len(texts))
+			vectors, err := s.embedTexts(ctx, texts[start:end])
+			if err != nil {
+				return nil, fmt.Errorf("failed to embed column '%s': %w", column, err)
+			}
+			for i, vector := range vectors {
+				rows[start+i][column] = RawSQL(s.Dialect.VectorLiteral(vector))
+			}
+		}
+	}
+	return data, nil
+}
+
+// copyRows returns a shallow copy of each row. The steps that change cell values before generating
+// (embeddings, blank policies, child rows) work on copies so that the loaded data is left untouched.
+func copyRows(data []map[string]interface{}) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(data))
+	for i, row := range data {
+		result[i] = make(map[string]interface{}, len(row))
+		for column, value := range row {
+			result[i][column] = value
+		}
+	}
+	return result
+}
+
+// sourceText returns the text of the column an embedding is computed from,
+// the column is matched by its raw name first and by its base name (GetColumnName) otherwise.
+func (s *Seeder) sourceText(row map[string]interface{}, sourceColumn string) string {
+	if value, ok := row[sourceColumn]; ok {
+		return s.Generator.StringValue(value)
+	}
+	for column, value := range row {
+		if !s.Adapter.IsEmbeddingColumn(column) && s.Generator.GetColumnName(column) == sourceColumn {
+			return s.Generator.StringValue(value)
+		}
+	}
+	return ""
+}
+
+// embedTexts returns one vector per text.
+func (s *Seeder) embedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	var models []string
+	if s.EmbedModel != "" {
+		models = append(models, s.EmbedModel)
+	}
+	vectors := make([][]float32, len(texts))
+	if s.EmbedBulk != nil {
+		result, err := s.EmbedBulk(ctx, texts, models...)
+		if err != nil {
+			return nil, err
+		}
+		if len(result) != len(texts) {
+			return nil, fmt.Errorf("EmbedBulk returned %d embeddings for %d texts", len(result), len(texts))
+		}
+		for i, embeddings := range result {
+			if len(embeddings) == 0 {
+				return nil, fmt.Errorf("EmbedBulk returned no embedding for '%s'", texts[i])
+			}
+			vectors[i] = embeddings[0]
+		}
+		return vectors, nil
+	}
+	if s.Embed == nil {
+		return nil, fmt.Errorf("Embed or EmbedBulk is required to seed embedding columns")
+	}
+	for i, text := range texts {
+		embeddings, err := s.Embed(ctx, text, models...)
+		if err != nil {
+			return nil, err
+		}
+		if len(embeddings) == 0 {
+			return nil, fmt.Errorf("Embed returned no embedding for '%s'", text)
+		}
+		vectors[i] = embeddings[0]
+	}
+	return vectors, nil
+}
@@ -0,0 +1,101 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeeder_SeedEmbeddingColumns(t *testing.T) {
+	var batches [][]string
+	embedSeeder := NewSeeder(SeederConfigInit{
+		EmbedBatchSize: 2,
+		EmbedModel:     "test-model",
+		EmbedBulk: func(ctx context.Context, texts []string, model ...string) ([][][]float32, error) {
+			require.Equal(t, []string{"test-model"}, model)
+			batches = append(batches, texts)
+			result := make([][][]float32, len(texts))
+			for i, text := range texts {
+				result[i] = [][]float32{{float32(len(text)), 0.5}}
+			}
+			return result, nil
+		},
+	})
+	content := `[
+		{"product_name": "Laptop", "description": "fast", "description_embedding~description": ""},
+		{"product_name": "Phone", "description": "small", "description_embedding~description": ""},
+		{"product_name": "Cable", "description": "", "description_embedding~description": ""},
+		{"product_name": "Mouse", "description": "wireless", "description_embedding~description": ""}
+	]`
+
+	result, err := embedSeeder.Seed(SeederConfig{
+		Loader:     JsonLoader{Content: *bytes.NewBufferString(content)},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"fast", "small"}, {"wireless"}}, batches)
+	require.Contains(t, result, "description_embedding")
+	require.NotContains(t, result, "~")
+	for _, literal := range []string{"'[4,0.5]'::vector", "'[5,0.5]'::vector", "'[8,0.5]'::vector"} {
+		require.Contains(t, result, literal)
+	}
+}
+
+func TestSeeder_SeedEmbeddingColumnsWithoutEmbedder(t *testing.T) {
+	_, err := seeder.Seed(SeederConfig{
+		Loader:     JsonLoader{Content: *bytes.NewBufferString(`[{"description": "fast", "description_embedding~description": ""}]`)},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	require.Error(t, err)
+}
+
+func TestSeeder_SeedEmbeddingColumnsKeepsLoadedRows(t *testing.T) {
+	embedSeeder := NewSeeder(SeederConfigInit{
+		Embed: func(ctx context.Context, text string, model ...string) ([][]float32, error) {
+			return [][]float32{{1, 2}}, nil
+		},
+	})
+	dataset := &Dataset{
+		Rows: []map[string]interface{}{
+			{"product_name": "Laptop", "description": "fast", "description_embedding~description": ""},
+			{"product_name": "Cable", "description": "", "description_embedding~description": ""},
+		},
+		FirstRow: 1,
+	}
+	config := SeederConfig{Loader: loadedDataset{dataset: dataset}, SchemaName: "public", TableName: "products"}
+
+	first, err := embedSeeder.Seed(config)
+	require.NoError(t, err)
+	second, err := embedSeeder.Seed(config)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+	require.Equal(t, "", dataset.Rows[0]["description_embedding~description"])
+	require.Equal(t, "", dataset.Rows[1]["description_embedding~description"])
+}
+
+func TestSeeder_EmbedColumnsWithoutBatchSize(t *testing.T) {
+	var batches [][]string
+	embedSeeder := NewSeeder(SeederConfigInit{
+		EmbedBulk: func(ctx context.Context, texts []string, model ...string) ([][][]float32, error) {
+			batches = append(batches, texts)
+			result := make([][][]float32, len(texts))
+			for i := range texts {
+				result[i] = [][]float32{{1}}
+			}
+			return result, nil
+		},
+	}).(*Seeder)
+	// a Seeder built without NewSeeder has no batch size
+	embedSeeder.EmbedBatchSize = 0
+
+	_, err := embedSeeder.embedColumns(context.Background(), []map[string]interface{}{
+		{"description": "fast", "description_embedding~description": ""},
+		{"description": "small", "description_embedding~description": ""},
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"fast", "small"}}, batches)
+}
@@ -0,0 +1,184 @@
+package sqlseeder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// CellError is an error located at a row and column of the seeded data.
+// The generator sets Row to the 1-based data row (0 for header errors), the seeder then
+// translates it to the row of the source and fills Source, Sheet and Cell.
+type CellError struct {
+	Source string // kind of source: "excel", "csv" or "json", empty when unknown
+	Sheet  string // sheet name of Excel sources
+	Row    int    // 1-based row number, 0 when the error is not about a single row
+	Column string // column header the error is about
+	Cell   string // cell reference (e.g. D14) for Excel and CSV sources
+	Err    error
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Location(), e.Err)
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// Location returns where the error happened, e.g. "excel products!D14" or "json row 3, column 'price'".
+func (e *CellError) Location() string {
+	parts := []string{}
+	if e.Source != "" {
+		parts = append(parts, e.Source)
+	}
+	switch {
+	case e.Cell != "" && e.Sheet != "":
+		parts = append(parts, fmt.Sprintf("%s!%s", e.Sheet, e.Cell))
+	case e.Cell != "":
+		parts = append(parts, e.Cell)
+	case e.Row > 0:
+		parts = append(parts, fmt.Sprintf("row %d", e.Row))
+	case e.Sheet != "":
+		parts = append(parts, e.Sheet)
+	}
+	location := strings.Join(parts, " ")
+	if e.Column != "" {
+		if location != "" {
+			location += ", "
+		}
+		location += fmt.Sprintf("column '%s'", e.Column)
+	}
+	return location
+}
+
+// CellErrors is returned when CollectErrors is set and one or more cells of the data are invalid.
+type CellErrors []*CellError
+
+func (e CellErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d invalid cells:\n%s", len(e), strings.Join(messages, "\n"))
+}
+
+func (e CellErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// UnresolvedLookupError is returned by Execute with StrictLookups when values looked up by a statement
+// don't match any row of their table.
+type UnresolvedLookupError struct {
+	Table   string
+	Missing []LookupCheck
+}
+
+func (e *UnresolvedLookupError) Error() string {
+	missing := make([]string, len(e.Missing))
+	for i, check := range e.Missing {
+		missing[i] = check.String()
+	}
+	return fmt.Sprintf("unresolved lookups in %s: %s", e.Table, strings.Join(missing, ", "))
+}
+
+// cellErrors returns the cell errors of err, errors that are not located yet are located at row and column.
+func cellErrors(err error, row int, column string) CellErrors {
+	var collected CellErrors
+	if errors.As(err, &collected) {
+		for _, cellErr := range collected {
+			if cellErr.Row == 0 {
+				cellErr.Row = row
+			}
+		}
+		return collected
+	}
+	var cellErr *CellError
+	if errors.As(err, &cellErr) {
+		if cellErr.Row == 0 {
+			cellErr.Row = row
+		}
+		return CellErrors{cellErr}
+	}
+	return CellErrors{{Row: row, Column: column, Err: err}}
+}
+
+// childRowsErrors locates the cell errors of child rows at the parent rows holding them, the child
+// table, row and column are kept in the message (e.g. order_lines row 2, column 'qty': ...).
+func childRowsErrors(err error, column string, table string, origins []childRowOrigin) CellErrors {
+	childErrs := cellErrors(err, 0, "")
+	located := make(CellErrors, len(childErrs))
+	for i, childErr := range childErrs {
+		row := 0
+		if childErr.Row > 0 && childErr.Row <= len(origins) {
+			origin := origins[childErr.Row-1]
+			row = origin.row
+			childErr.Row = origin.index
+		}
+		message := table
+		if location := childErr.Location(); location != "" {
+			message += " " + location
+		}
+		located[i] = &CellError{Row: row, Column: column, Err: fmt.Errorf("%s: %w", message, childErr.Err)}
+	}
+	return located
+}
+
+// locate translates the data rows of the cell errors in err to source rows and cell references.
+func (d *Dataset) locate(err error) error {
+	var collected CellErrors
+	if !errors.As(err, &collected) {
+		var cellErr *CellError
+		if !errors.As(err, &cellErr) {
+			return err
+		}
+		collected = CellErrors{cellErr}
+	}
+	for _, cellErr := range collected {
+		d.locateCell(cellErr)
+	}
+	return err
+}
+
+// locateCell translates the data row of a cell to its source row and cell reference.
+func (d *Dataset) locateCell(cellErr *CellError) {
+	cellErr.Source = d.Source
+	cellErr.Sheet = d.Sheet
+	if index := cellErr.Row - 1 + d.offset; cellErr.Row > 0 && index < len(d.RowNumbers) {
+		cellErr.Row = d.RowNumbers[index]
+	} else if d.FirstRow > 0 {
+		// header errors are located at the row before the first data row
+		if cellErr.Row > 0 {
+			cellErr.Row += d.offset
+		}
+		cellErr.Row += d.FirstRow - 1
+	}
+	if cellErr.Row == 0 || (d.Source != SourceExcel && d.Source != SourceCSV) {
+		return
+	}
+	for index, column := range d.Columns {
+		if column == cellErr.Column {
+			cellErr.Cell, _ = excelize.CoordinatesToCellName(index+1, cellErr.Row)
+			break
+		}
+	}
+}
+
+// locateLookups translates the source cells of the lookups of the statements to source rows and cell references.
+func (d *Dataset) locateLookups(data *SQLData) {
+	for _, stmt := range data.Statements {
+		for _, row := range stmt.Rows {
+			for _, value := range row {
+				if lookup, ok := value.(SQLExpr); ok && lookup.Source != nil {
+					d.locateCell(lookup.Source)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeeder_SeedErrorCoordinates(t *testing.T) {
+	sheets := map[string][][]interface{}{
+		"products": {
+			{"product_name", "created_at", "tag_id***tags"},
+			{"Laptop", "sql:now()", "new"},
+			{"Mouse", "2024-01-01", "new"},
+			{"Phone", "sql:now()", "new"},
+		},
+	}
+	config := func() SeederConfig {
+		return SeederConfig{
+			Loader:     ExcelLoader{Content: newTestWorkbook(t, sheets, []string{"products"}), SheetName: "products"},
+			SchemaName: "public",
+			TableName:  "products",
+		}
+	}
+
+	_, err := NewSeeder(SeederConfigInit{RawSQLPrefix: RawSQLMarker, DisableRawSQL: true}).Seed(config())
+	var cellErr *CellError
+	require.True(t, errors.As(err, &cellErr))
+	require.Equal(t, "excel products!C1, column 'tag_id***tags': not valid many to many column name: tag_id***tags", err.Error())
+
+	_, err = NewSeeder(SeederConfigInit{RawSQLPrefix: RawSQLMarker, DisableRawSQL: true, CollectErrors: true}).Seed(config())
+	var cellErrs CellErrors
+	require.True(t, errors.As(err, &cellErrs))
+	require.Len(t, cellErrs, 3)
+	require.Equal(t, "products!C1", cellErrs[0].Sheet+"!"+cellErrs[0].Cell)
+	require.Equal(t, CellError{Source: SourceExcel, Sheet: "products", Row: 2, Column: "created_at", Cell: "B2", Err: cellErrs[1].Err}, *cellErrs[1])
+	require.Equal(t, "B4", cellErrs[2].Cell)
+	require.EqualError(t, cellErrs[2], "excel products!B4, column 'created_at': raw SQL is disabled, found the raw expression 'now()'")
+}
+
+func TestSeeder_SeedErrorCoordinatesJSONAndCSV(t *testing.T) {
+	_, err := seeder.Seed(SeederConfig{
+		Loader: JsonLoader{Content: *bytes.NewBufferString(`[
+			{"product_name": "Laptop", "tag_id***product_tags***tags***tag_name***product_name": "new"},
+			{"product_name": "", "tag_id***product_tags***tags***tag_name***product_name": "new|sale"},
+			{"product_name": "", "tag_id***product_tags***tags***tag_name***product_name": ""}
+		]`)},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	require.EqualError(t, err, "json row 2, column 'tag_id***product_tags***tags***tag_name***product_name': the 'product_name' value looking up the products row is empty")
+
+	csvSeeder := NewSeeder(SeederConfigInit{RawSQLPrefix: RawSQLMarker, RawSQLAllowlist: []string{"now"}})
+	_, err = csvSeeder.Seed(SeederConfig{
+		Loader:     CSVLoader{Content: *bytes.NewBufferString("name,created_at\nLaptop,sql:now()\nMouse,sql:pg_sleep(1)\n")},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	require.EqualError(t, err, "csv B3, column 'created_at': raw SQL 'pg_sleep(1)' is not allowed: function 'pg_sleep' is not in the raw SQL allowlist")
+}
+
+func TestSeeder_SeedErrorCoordinatesCSVLines(t *testing.T) {
+	// the Laptop record spans lines 3 and 4 after a blank line, Mouse starts on line 5
+	content := "name,created_at,tag_id***tags\n\nLaptop,\"2024-01-01\n10:00\",new\nMouse,sql:pg_sleep(1),new\n"
+	csvSeeder := NewSeeder(SeederConfigInit{
+		RawSQLPrefix:        RawSQLMarker,
+		RawSQLAllowlist:     []string{"now"},
+		CollectErrors:       true,
+		MaxRowsPerStatement: 1,
+	})
+	config := SeederConfig{
+		Loader:     CSVLoader{Content: *bytes.NewBufferString(content)},
+		SchemaName: "public",
+		TableName:  "products",
+	}
+
+	_, err := csvSeeder.Seed(config)
+	var cellErrs CellErrors
+	require.True(t, errors.As(err, &cellErrs))
+	require.Len(t, cellErrs, 2)
+	require.Equal(t, []string{"C1", "B5"}, []string{cellErrs[0].Cell, cellErrs[1].Cell})
+	require.Equal(t, 5, cellErrs[1].Row)
+
+	// batches of one row are located at the lines of their records too
+	err = csvSeeder.SeedTo(&bytes.Buffer{}, config)
+	require.True(t, errors.As(err, &cellErrs))
+	require.Len(t, cellErrs, 2)
+	require.Equal(t, []string{"C1", "B5"}, []string{cellErrs[0].Cell, cellErrs[1].Cell})
+}
+
+func TestSeeder_SeedToErrorCoordinates(t *testing.T) {
+	sheet := [][]interface{}{{"product_name", "created_at"}}
+	for _, created := range []string{"2024-01-01", "2024-01-02", "2024-01-03", "sql:now()"} {
+		sheet = append(sheet, []interface{}{"p", created})
+	}
+	content := newTestWorkbook(t, map[string][][]interface{}{"products": sheet}, []string{"products"})
+
+	var script bytes.Buffer
+	err := NewSeeder(SeederConfigInit{RawSQLPrefix: RawSQLMarker, DisableRawSQL: true, MaxRowsPerStatement: 2}).SeedTo(&script, SeederConfig{
+		Loader:     ExcelLoader{Content: content, SheetName: "products"},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	var cellErr *CellError
+	require.True(t, errors.As(err, &cellErr))
+	require.Equal(t, "B5", cellErr.Cell)
+	require.Equal(t, 5, cellErr.Row)
+
+	// skipped blank rows still count
+	sheet = append(sheet[:3], append([][]interface{}{{}, {"", ""}}, sheet[3:]...)...)
+	content = newTestWorkbook(t, map[string][][]interface{}{"products": sheet}, []string{"products"})
+	blankRowsSeeder := NewSeeder(SeederConfigInit{RawSQLPrefix: RawSQLMarker, DisableRawSQL: true, MaxRowsPerStatement: 2})
+	config := SeederConfig{
+		Loader:     ExcelLoader{Content: content, SheetName: "products"},
+		SchemaName: "public",
+		TableName:  "products",
+	}
+	err = blankRowsSeeder.SeedTo(&bytes.Buffer{}, config)
+	require.True(t, errors.As(err, &cellErr))
+	require.Equal(t, "B7", cellErr.Cell)
+	config.Loader = ExcelLoader{Content: *bytes.NewBuffer(content.Bytes()), SheetName: "products"}
+	_, err = blankRowsSeeder.Seed(config)
+	require.True(t, errors.As(err, &cellErr))
+	require.Equal(t, "B7", cellErr.Cell)
+}
+
+func TestSeeder_SeedToCollectErrors(t *testing.T) {
+	sheet := [][]interface{}{{"product_name", "created_at", "tag_id***tags"}}
+	for _, created := range []string{"sql:now()", "2024-01-02", "2024-01-03", "sql:now()", "2024-01-05"} {
+		sheet = append(sheet, []interface{}{"p", created, "new"})
+	}
+	content := newTestWorkbook(t, map[string][][]interface{}{"products": sheet}, []string{"products"})
+
+	var script bytes.Buffer
+	err := NewSeeder(SeederConfigInit{
+		RawSQLPrefix:        RawSQLMarker,
+		DisableRawSQL:       true,
+		CollectErrors:       true,
+		MaxRowsPerStatement: 2,
+	}).SeedTo(&script, SeederConfig{
+		Loader:     ExcelLoader{Content: content, SheetName: "products"},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	var cellErrs CellErrors
+	require.True(t, errors.As(err, &cellErrs))
+	// the header error of every batch is reported once, the cell errors of the first and second batch together
+	cells := make([]string, len(cellErrs))
+	for i, cellErr := range cellErrs {
+		cells[i] = cellErr.Cell
+	}
+	require.Equal(t, []string{"C1", "B2", "B5"}, cells)
+	require.Empty(t, script.String())
+}
@@ -0,0 +1,197 @@
+package sqlseeder
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// SQLExecutor runs statements against a database, it is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type SQLExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// SQLQueryer runs queries against a database, it is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+// Execute needs it to check lookups when StrictLookups is set.
+type SQLQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// transactionStarter is implemented by *sql.DB and *sql.Conn, Execute runs their statements in a transaction.
+type transactionStarter interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// ExecutedStatement reports a statement run by Execute.
+type ExecutedStatement struct {
+	Schema       string
+	Table        string
+	SQL          string
+	RowsAffected int64 // -1 when the driver doesn't report affected rows
+}
+
+// ExecutionResult reports every statement run by Execute.
+type ExecutionResult struct {
+	Statements []ExecutedStatement
+}
+
+// RowsAffected returns the total rows affected by the executed statements.
+func (r *ExecutionResult) RowsAffected() int64 {
+	var total int64
+	for _, statement := range r.Statements {
+		if statement.RowsAffected > 0 {
+			total += statement.RowsAffected
+		}
+	}
+	return total
+}
+
+// StatementError reports the statement that failed during Execute.
+type StatementError struct {
+	Index int // 0-based position of the statement in the execution
+	Table string
+	SQL   string
+	Err   error
+}
+
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("statement %d on %s failed: %v", e.Index+1, e.Table, e.Err)
+}
+
+func (e *StatementError) Unwrap() error {
+	return e.Err
+}
+
+// Execute loads the configs, orders them like SeedAll and runs the generated statements with bound
+// parameters instead of interpolated literals.
+// When db is a *sql.DB or a *sql.Conn every statement runs in a single transaction that is rolled back on
+// the first failure, any other executor (e.g. a *sql.Tx) is used as it is and the caller owns the transaction.
+// The partial result collected before a failure is returned together with a *StatementError.
+// With StrictLookups the lookup values of every statement are checked right before it runs and the
+// values that match no row are reported as an *UnresolvedLookupError.
+func (s *Seeder) Execute(ctx context.Context, db SQLExecutor, configs ...SeederConfig) (*ExecutionResult, error) {
+	statements, err := s.SeedParameterized(configs...)
+	if err != nil {
+		return nil, err
+	}
+
+	executor := db
+	var tx *sql.Tx
+	if database, ok := db.(transactionStarter); ok {
+		tx, err = database.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		executor = tx
+	}
+
+	result := &ExecutionResult{}
+	for i, statement := range statements {
+		err := checkLookups(ctx, executor, statement)
+		var execResult sql.Result
+		if err == nil {
+			execResult, err = executor.ExecContext(ctx, statement.SQL, statement.Args...)
+		}
+		if err != nil {
+			if tx != nil {
+				_ = tx.Rollback()
+			}
+			return result, &StatementError{Index: i, Table: statementName(statement), SQL: statement.SQL, Err: err}
+		}
+		rowsAffected, err := execResult.RowsAffected()
+		if err != nil {
+			rowsAffected = -1
+		}
+		result.Statements = append(result.Statements, ExecutedStatement{
+			Schema:       statement.Schema,
+			Table:        statement.Table,
+			SQL:          statement.SQL,
+			RowsAffected: rowsAffected,
+		})
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return result, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// SeedParameterized loads and orders the configs like SeedAll and returns their statements with dialect
+// placeholders ($1 / ? / @p1) and the ordered arguments of each statement, ready for database/sql,
+// pgx or sqlx batch APIs. Function configs return a single statement with the JSON data as argument.
+func (s *Seeder) SeedParameterized(configs ...SeederConfig) ([]ParameterizedStatement, error) {
+	configs, datasets, err := s.loadOrdered(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	seeded := s.newSeededRows(configs, datasets)
+	var statements []ParameterizedStatement
+	for i, config := range configs {
+		if config.FunctionName != "" {
+			jsonBytes, err := json.Marshal(datasets[i].Rows)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal data to JSON: %w", err)
+			}
+			statements = append(statements, ParameterizedStatement{
+				Table: config.FunctionName,
+				SQL:   s.Dialect.FunctionCall(config.FunctionName, s.Dialect.Placeholder(1)),
+				Args:  []interface{}{string(jsonBytes)},
+			})
+			continue
+		}
+		sqlData, err := s.buildSQLData(config, datasets[i], seeded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seed %s: %w", configName(config), err)
+		}
+		tableStatements, err := s.Generator.GenerateParameterized(*sqlData)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, tableStatements...)
+	}
+	return statements, nil
+}
+
+// checkLookups runs the lookup checks of a statement and reports the values that match no row.
+// Rows seeded by the previous statements of the transaction are found as well.
+func checkLookups(ctx context.Context, executor SQLExecutor, statement ParameterizedStatement) error {
+	if len(statement.Lookups) == 0 {
+		return nil
+	}
+	queryer, ok := executor.(SQLQueryer)
+	if !ok {
+		return fmt.Errorf("the executor can't run queries to check lookups")
+	}
+	var missing []LookupCheck
+	for _, check := range statement.Lookups {
+		rows, err := queryer.QueryContext(ctx, check.SQL, check.Args...)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", check.String(), err)
+		}
+		found := rows.Next()
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", check.String(), err)
+		}
+		if !found {
+			missing = append(missing, check)
+		}
+	}
+	if len(missing) > 0 {
+		return &UnresolvedLookupError{Table: statementName(statement), Missing: missing}
+	}
+	return nil
+}
+
+// statementName returns the table name used to refer to a statement in errors.
+func statementName(statement ParameterizedStatement) string {
+	if statement.Schema == "" {
+		return statement.Table
+	}
+	return fmt.Sprintf("%s.%s", statement.Schema, statement.Table)
+}
@@ -0,0 +1,405 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestDatabase(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	// keep a single connection so every statement sees the same in-memory database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(`
+		CREATE TABLE categories (category_id INTEGER PRIMARY KEY, category_name TEXT UNIQUE NOT NULL);
+		CREATE TABLE products (product_id INTEGER PRIMARY KEY, product_name TEXT NOT NULL, price REAL, category_id INTEGER);
+	`)
+	require.NoError(t, err)
+	return db
+}
+
+func sqliteConfig(tableName string, content string) SeederConfig {
+	return SeederConfig{
+		Loader:     JsonLoader{Content: *bytes.NewBufferString(content)},
+		SchemaName: "main",
+		TableName:  tableName,
+	}
+}
+
+func TestSeeder_Execute(t *testing.T) {
+	db := newTestDatabase(t)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}})
+
+	result, err := sqliteSeeder.Execute(context.Background(), db,
+		sqliteConfig("products", `[
+			{"product_name": "Laptop", "price": 999.5, "category_id**categories**category_name": "Electronics"},
+			{"product_name": "Robert'); DROP TABLE products;--", "price": null, "category_id**categories**category_name": "Books"}
+		]`),
+		sqliteConfig("categories", `[{"category_name": "Electronics"}, {"category_name": "Books"}]`),
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Statements, 2)
+	require.Equal(t, "categories", result.Statements[0].Table)
+	require.Equal(t, int64(2), result.Statements[0].RowsAffected)
+	require.Equal(t, int64(2), result.Statements[1].RowsAffected)
+	require.Equal(t, int64(4), result.RowsAffected())
+	require.NotContains(t, result.Statements[1].SQL, "Laptop")
+
+	rows, err := db.Query(`SELECT p.product_name, p.price, c.category_name FROM products p JOIN categories c ON c.category_id = p.category_id ORDER BY p.product_id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var seeded [][]interface{}
+	for rows.Next() {
+		var name, category string
+		var price sql.NullFloat64
+		require.NoError(t, rows.Scan(&name, &price, &category))
+		seeded = append(seeded, []interface{}{name, price, category})
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, [][]interface{}{
+		{"Laptop", sql.NullFloat64{Float64: 999.5, Valid: true}, "Electronics"},
+		{"Robert'); DROP TABLE products;--", sql.NullFloat64{}, "Books"},
+	}, seeded)
+}
+
+func TestSeeder_ExecuteRollsBackOnError(t *testing.T) {
+	db := newTestDatabase(t)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}})
+
+	result, err := sqliteSeeder.Execute(context.Background(), db,
+		sqliteConfig("categories", `[{"category_name": "Electronics"}]`),
+		sqliteConfig("products", `[{"product_title": "Laptop"}]`),
+	)
+	var statementErr *StatementError
+	require.True(t, errors.As(err, &statementErr))
+	require.Equal(t, 1, statementErr.Index)
+	require.Equal(t, "main.products", statementErr.Table)
+	require.Len(t, result.Statements, 1)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM categories`).Scan(&count))
+	require.Equal(t, 0, count)
+}
+
+func TestSeeder_ExecuteRollsBackOnConnection(t *testing.T) {
+	db := newTestDatabase(t)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}})
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = sqliteSeeder.Execute(ctx, conn,
+		sqliteConfig("categories", `[{"category_name": "Electronics"}]`),
+		sqliteConfig("products", `[{"product_title": "Laptop"}]`),
+	)
+	var statementErr *StatementError
+	require.True(t, errors.As(err, &statementErr))
+
+	var count int
+	require.NoError(t, conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM categories`).Scan(&count))
+	require.Equal(t, 0, count)
+}
+
+func TestSeeder_ExecuteInCallerTransaction(t *testing.T) {
+	db := newTestDatabase(t)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}})
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = sqliteSeeder.Execute(context.Background(), tx, sqliteConfig("categories", `[{"category_name": "Books"}]`))
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM categories`).Scan(&count))
+	require.Equal(t, 0, count)
+}
+
+func TestSeeder_ExecuteManyToManyWildcard(t *testing.T) {
+	db := newTestDatabase(t)
+	_, err := db.Exec(`CREATE TABLE product_categories (product_id INTEGER, category_id INTEGER, is_primary INTEGER, PRIMARY KEY (product_id, category_id))`)
+	require.NoError(t, err)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}})
+
+	_, err = sqliteSeeder.Execute(context.Background(), db,
+		sqliteConfig("categories", `[{"category_name": "Electronics"}, {"category_name": "Books"}, {"category_name": "Toys"}]`),
+		sqliteConfig("products", `[
+			{"product_name": "Laptop", "category_id***product_categories***categories***category_name***product_name***is_primary": "*:0"},
+			{"product_name": "Mouse", "category_id***product_categories***categories***category_name***product_name***is_primary": "Books:1"},
+			{"product_name": "Cable", "category_id***product_categories***categories***category_name***product_name***is_primary": "*"}
+		]`),
+	)
+	require.NoError(t, err)
+
+	var laptop, mouse, cable int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM product_categories WHERE product_id = 1 AND is_primary = 0`).Scan(&laptop))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM product_categories WHERE product_id = 2 AND is_primary = 1`).Scan(&mouse))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM product_categories WHERE product_id = 3 AND is_primary IS NULL`).Scan(&cable))
+	require.Equal(t, 3, laptop)
+	require.Equal(t, 1, mouse)
+	require.Equal(t, 3, cable)
+}
+
+func TestSeeder_ExecuteOneToManyWildcard(t *testing.T) {
+	db := newTestDatabase(t)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}})
+
+	// SQLite would take the first of the categories as the value of the subquery
+	_, err := sqliteSeeder.Execute(context.Background(), db,
+		sqliteConfig("categories", `[{"category_name": "Electronics"}, {"category_name": "Books"}]`),
+		sqliteConfig("products", `[{"product_name": "Laptop", "category_id**categories**category_name": "*"}]`),
+	)
+	require.EqualError(t, err, "failed to seed main.products: json row 1, column 'category_id**categories**category_name': the lookup wildcard '*' selects every categories row, it can only be used in many-to-many cells")
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM products`).Scan(&count))
+	require.Equal(t, 0, count)
+}
+
+func TestSeeder_ExecuteManyToManyAttributes(t *testing.T) {
+	db := newTestDatabase(t)
+	_, err := db.Exec(`
+		CREATE TABLE tags (tag_id INTEGER PRIMARY KEY, tag_name TEXT UNIQUE NOT NULL);
+		CREATE TABLE product_tags (product_id INTEGER, tag_id INTEGER, sort_order INTEGER, is_primary BOOLEAN, PRIMARY KEY (product_id, tag_id));
+	`)
+	require.NoError(t, err)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}, RawSQLPrefix: RawSQLMarker})
+
+	_, err = sqliteSeeder.Execute(context.Background(), db,
+		sqliteConfig("tags", `[{"tag_name": "tag1"}, {"tag_name": "tag2"}, {"tag_name": "tag3"}]`),
+		sqliteConfig("products", `[
+			{"product_name": "Laptop", "tag_id***product_tags***tags***tag_name***product_name***sort_order:is_primary": "tag1:1:true | tag2:2"},
+			{"product_name": "Mouse", "tag_id***product_tags***tags***tag_name***product_name***sort_order:is_primary": "tag3:sql:10 * 2:EMPTY"}
+		]`),
+	)
+	require.NoError(t, err)
+
+	rows, err := db.Query(`SELECT p.product_name, t.tag_name, pt.sort_order, typeof(pt.sort_order), pt.is_primary, typeof(pt.is_primary)
+		FROM product_tags pt JOIN products p USING (product_id) JOIN tags t USING (tag_id) ORDER BY t.tag_name`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var joins []string
+	for rows.Next() {
+		var product, tag, sortOrderType, isPrimaryType string
+		var sortOrder, isPrimary interface{}
+		require.NoError(t, rows.Scan(&product, &tag, &sortOrder, &sortOrderType, &isPrimary, &isPrimaryType))
+		joins = append(joins, fmt.Sprintf("%s:%s:%v(%s):%v(%s)", product, tag, sortOrder, sortOrderType, isPrimary, isPrimaryType))
+	}
+	require.NoError(t, rows.Err())
+	// numbers and booleans are stored typed, a missing attribute is NULL, EMPTY is an empty string and raw SQL is evaluated
+	require.Equal(t, []string{"Laptop:tag1:1(integer):1(integer)", "Laptop:tag2:2(integer):<nil>(null)", "Mouse:tag3:20(integer):(text)"}, joins)
+}
+
+func TestSeeder_ExecuteMissingValues(t *testing.T) {
+	content := `[{"product_name": "Laptop", "price": 10}, {"product_name": "Mouse", "sku": "m1"}, {"product_name": "Pad", "sku": "p1"}]`
+	seedPrices := func(missing MissingValue) []string {
+		db := newTestDatabase(t)
+		_, err := db.Exec(`CREATE TABLE items (item_id INTEGER PRIMARY KEY, product_name TEXT NOT NULL, price REAL DEFAULT 5, sku TEXT DEFAULT 'none')`)
+		require.NoError(t, err)
+		// SQLite has no DEFAULT keyword so defaulted rows are inserted by separate statements
+		sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}, MissingValue: missing})
+		_, err = sqliteSeeder.Execute(context.Background(), db, sqliteConfig("items", content))
+		require.NoError(t, err)
+
+		rows, err := db.Query(`SELECT product_name, price, sku FROM items ORDER BY product_name`)
+		require.NoError(t, err)
+		defer rows.Close()
+		var items []string
+		for rows.Next() {
+			var name string
+			var price, sku interface{}
+			require.NoError(t, rows.Scan(&name, &price, &sku))
+			items = append(items, fmt.Sprintf("%s:%v:%v", name, price, sku))
+		}
+		require.NoError(t, rows.Err())
+		return items
+	}
+
+	require.Equal(t, []string{"Laptop:10:<nil>", "Mouse:<nil>:m1", "Pad:<nil>:p1"}, seedPrices(MissingValueNull))
+	require.Equal(t, []string{"Laptop:10:none", "Mouse:5:m1", "Pad:5:p1"}, seedPrices(MissingValueDefault))
+}
+
+func TestSeeder_ExecuteStrictLookups(t *testing.T) {
+	db := newTestDatabase(t)
+	strictSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}, StrictLookups: true})
+
+	products := `[
+		{"product_name": "Laptop", "category_id**categories**category_name": "Electronics"},
+		{"product_name": "Phone", "category_id**categories**category_name": "Electonics"},
+		{"product_name": "Novel", "category_id**categories**category_name": "Books"},
+		{"product_name": "Tablet", "category_id**categories**category_name": "Electonics"}
+	]`
+	_, err := strictSeeder.Execute(context.Background(), db,
+		sqliteConfig("categories", `[{"category_name": "Electronics"}]`),
+		sqliteConfig("products", products),
+	)
+	var lookupErr *UnresolvedLookupError
+	require.True(t, errors.As(err, &lookupErr))
+	require.EqualError(t, err, "statement 2 on main.products failed: unresolved lookups in main.products: "+
+		"categories.category_name = 'Electonics' (json row 2, column 'category_id**categories**category_name'), "+
+		"categories.category_name = 'Books' (json row 3, column 'category_id**categories**category_name')")
+	require.Equal(t, "category_id**categories**category_name", lookupErr.Missing[0].Column)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM categories`).Scan(&count))
+	require.Equal(t, 0, count)
+
+	// categories seeded earlier in the same run resolve the lookups
+	_, err = strictSeeder.Execute(context.Background(), db,
+		sqliteConfig("categories", `[{"category_name": "Electronics"}, {"category_name": "Electonics"}, {"category_name": "Books"}]`),
+		sqliteConfig("products", products),
+	)
+	require.NoError(t, err)
+}
+
+func TestSeeder_ExecuteAutoCreate(t *testing.T) {
+	db := newTestDatabase(t)
+	_, err := db.Exec(`
+		INSERT INTO categories (category_id, category_name) VALUES (5, 'Books');
+		CREATE TABLE tags (tag_id INTEGER PRIMARY KEY, tag_name TEXT NOT NULL);
+		INSERT INTO tags (tag_name) VALUES ('sale');
+		CREATE TABLE product_tags (product_id INTEGER NOT NULL, tag_id INTEGER NOT NULL, PRIMARY KEY (product_id, tag_id));
+	`)
+	require.NoError(t, err)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}, StrictLookups: true})
+
+	// tags has no unique key on tag_name, existing tags are skipped by the NOT EXISTS check
+	_, err = sqliteSeeder.Execute(context.Background(), db, sqliteConfig("products", `[
+		{"product_name": "Laptop", "category_id**categories+**category_name": "Electronics", "tag_id***product_tags***tags+***tag_name***product_name": "new|sale"},
+		{"product_name": "Novel", "category_id**categories+**category_name": "Books", "tag_id***product_tags***tags+***tag_name***product_name": "sale"}
+	]`))
+	require.NoError(t, err)
+
+	var categories, tags, joins int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM categories`).Scan(&categories))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM tags`).Scan(&tags))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM product_tags`).Scan(&joins))
+	require.Equal(t, 2, categories)
+	require.Equal(t, 2, tags)
+	require.Equal(t, 3, joins)
+
+	var category int
+	require.NoError(t, db.QueryRow(`SELECT category_id FROM products WHERE product_name = 'Novel'`).Scan(&category))
+	require.Equal(t, 5, category)
+}
+
+func TestSeeder_ExecuteAutoCreateSelfReference(t *testing.T) {
+	db := newTestDatabase(t)
+	_, err := db.Exec(`CREATE TABLE sections (section_id INTEGER PRIMARY KEY, section_name TEXT NOT NULL, parent_id INTEGER)`)
+	require.NoError(t, err)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}})
+
+	// Electronics is inserted by the sections rows, only Gadgets is missing
+	result, err := sqliteSeeder.Execute(context.Background(), db, sqliteConfig("sections", `[
+		{"section_name": "Electronics"},
+		{"section_name": "Laptops", "parent_id**sections+**section_name": "Electronics"},
+		{"section_name": "Phones", "parent_id**sections+**section_name": "Gadgets"}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, result.Statements, 2)
+
+	rows, err := db.Query(`SELECT section_name FROM sections ORDER BY section_id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var sections []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		sections = append(sections, name)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"Gadgets", "Electronics", "Laptops", "Phones"}, sections)
+}
+
+func TestSeeder_ExecuteChildRows(t *testing.T) {
+	db := newTestDatabase(t)
+	_, err := db.Exec(`
+		CREATE TABLE orders (order_id INTEGER PRIMARY KEY, order_number TEXT UNIQUE NOT NULL);
+		CREATE TABLE order_lines (line_id INTEGER PRIMARY KEY, order_id INTEGER NOT NULL, product_id INTEGER, qty INTEGER);
+	`)
+	require.NoError(t, err)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}, StrictLookups: true})
+
+	_, err = sqliteSeeder.Execute(context.Background(), db,
+		sqliteConfig("orders", `[
+			{"order_number": "A1", "order_lines>order_number": [{"product_id**products**product_name": "Laptop", "qty": 2}, {"qty": 1}]},
+			{"order_number": "A2", "order_lines>order_number": []},
+			{"order_number": "A3", "order_lines>order_number": [{"product_id**products**product_name": "Laptop", "qty": 5}]}
+		]`),
+		sqliteConfig("products", `[{"product_name": "Laptop"}]`),
+	)
+	require.NoError(t, err)
+
+	rows, err := db.Query(`SELECT o.order_number, l.product_id, l.qty FROM order_lines l JOIN orders o USING (order_id) ORDER BY l.line_id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var number string
+		var product sql.NullInt64
+		var qty int
+		require.NoError(t, rows.Scan(&number, &product, &qty))
+		lines = append(lines, fmt.Sprintf("%s:%d:%d", number, product.Int64, qty))
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"A1:1:2", "A1:0:1", "A3:1:5"}, lines)
+}
+
+func TestSeeder_ExecuteChildRowsSchema(t *testing.T) {
+	db := newTestDatabase(t)
+	_, err := db.Exec(`
+		ATTACH DATABASE ':memory:' AS sales;
+		CREATE TABLE sales.orders (order_id INTEGER PRIMARY KEY, order_number TEXT UNIQUE NOT NULL);
+		CREATE TABLE sales.order_lines (line_id INTEGER PRIMARY KEY, order_id INTEGER NOT NULL, qty INTEGER, note TEXT);
+		CREATE TABLE sales.order_notes (note_id INTEGER PRIMARY KEY, order_id INTEGER NOT NULL, body TEXT);
+		CREATE TABLE order_notes (note_id INTEGER PRIMARY KEY, order_id INTEGER NOT NULL, body TEXT);
+	`)
+	require.NoError(t, err)
+	sqliteSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}})
+	config := SeederConfig{
+		Loader: JsonLoader{Content: *bytes.NewBufferString(`[
+			{"order_number": "A1", "order_lines>order_number": [{"qty": 2, "note": "gift"}, {"note": "spare", "qty": 1}], "main.order_notes>order_number": [{"body": "call first"}]}
+		]`)},
+		SchemaName: "sales",
+		TableName:  "orders",
+	}
+
+	result, err := sqliteSeeder.Execute(context.Background(), db, config)
+	require.NoError(t, err)
+	// child tables are in the parent schema unless the header names one, their columns follow the object keys
+	require.Len(t, result.Statements, 3)
+	require.Contains(t, result.Statements[1].SQL, "INSERT OR IGNORE INTO sales.order_lines (order_id, qty, note)")
+	require.Contains(t, result.Statements[2].SQL, "INSERT OR IGNORE INTO main.order_notes (order_id, body)")
+
+	var lines, notes, otherNotes int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sales.order_lines l JOIN sales.orders o USING (order_id) WHERE o.order_number = 'A1'`).Scan(&lines))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM main.order_notes`).Scan(&notes))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sales.order_notes`).Scan(&otherNotes))
+	require.Equal(t, 2, lines)
+	require.Equal(t, 1, notes)
+	require.Equal(t, 0, otherNotes)
+}
+
+func TestSeeder_SeedParameterized(t *testing.T) {
+	statements, err := seeder.SeedParameterized(
+		jsonConfig("products", `[{"product_name": "Laptop", "category_id**categories**category_name": "Electronics"}]`),
+		jsonConfig("categories", `[{"category_name": "Electronics"}]`),
+		SeederConfig{Loader: JsonLoader{Content: *bytes.NewBufferString(`[{"name": "x"}]`)}, FunctionName: "seed_items"},
+	)
+	require.NoError(t, err)
+	require.Len(t, statements, 3)
+	require.Equal(t, "categories", statements[0].Table)
+	require.Equal(t, []interface{}{"Electronics"}, statements[0].Args)
+	require.Equal(t, "products", statements[1].Table)
+	require.Equal(t, []interface{}{"Laptop", "Electronics"}, statements[1].Args)
+	require.Equal(t, ParameterizedStatement{Table: "seed_items", SQL: "SELECT seed_items($1::JSONB);", Args: []interface{}{`[{"name":"x"}]`}}, statements[2])
+}
@@ -17,7 +17,7 @@ func hashPassword(pass string) string {
 }
 func main() {
 	// Create an instance of SQLGenerator
-	seeder := sqlseeder.NewSeeder(sqlseeder.SeederConfig{
+	seeder := sqlseeder.NewSeeder(sqlseeder.SeederConfigInit{
 		HashFunc: hashPassword,
 	})
 	// Create an instance of SeederImpl with the generator
@@ -92,7 +92,14 @@ func main() {
 		log.Fatal().Err(err).Msg("cano open the file")
 	}
 
-	excelString, err := seeder.SeedFromExcel(*bytes.NewBuffer(excelFile), "accounts_schema", "roles", "roles", map[string]string{})
+	excelString, err := seeder.Seed(sqlseeder.SeederConfig{
+		Loader: sqlseeder.ExcelLoader{
+			Content:   *bytes.NewBuffer(excelFile),
+			SheetName: "roles",
+		},
+		SchemaName: "accounts_schema",
+		TableName:  "roles",
+	})
 	if err != nil {
 		log.Fatal().Err(err).Msg("cano seed from excel")
 	}
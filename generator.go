@@ -2,8 +2,16 @@ package sqlseeder
 
 import (
 	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -17,21 +25,61 @@ type GeneratorInterface interface {
 	// for a given schema and table.
 	GenerateTableData(data []map[string]interface{}, schemaName string, tableName string) (*SQLData, error)
 
+	// GenerateOrderedTableData generates SQLData like GenerateTableData keeping the source order of the columns.
+	GenerateOrderedTableData(data []map[string]interface{}, columns []string, schemaName string, tableName string) (*SQLData, error)
+
 	// GenerateRootTableData generates a map representing a single row of data for root columns
 	// (columns that are not part of many-to-many relationships).
+	// The row maps every root column to its value: the typed cell value, an SQLExpr for lookups,
+	// RawSQL for raw expressions or the MissingValue policy value. Adapter.FormatValue renders a value as SQL.
 	GenerateRootTableDataRow(rootColumns []string, row map[string]interface{}, tableName string) (map[string]interface{}, error)
 	// GetColumnName extracts the base column name (the part before any delimiters).
 	GetColumnName(column string) string
 
+	// StringValue converts a typed cell value to its text.
+	StringValue(value interface{}) string
+
 	// Generate generates the SQL insert statements from the provided SQLData.
 	Generate(model SQLData) (string, error)
 
+	// GenerateTo writes the SQL insert statements of the provided SQLData to w.
+	GenerateTo(w io.Writer, model SQLData) error
+
+	// ParseTemplate loads and parses the insert template with the template functions.
+	ParseTemplate() (*template.Template, error)
+
+	// GenerateWithTemplate writes the SQL insert statements of the provided SQLData to w with a template
+	// returned by ParseTemplate, so that it is parsed once for many calls.
+	GenerateWithTemplate(w io.Writer, tmpl *template.Template, model SQLData) error
+
 	// GenerateOneToManySubquery generates a subquery for a one-to-many relationship column.
 	GenerateOneToManySubquery(columnName string, tableName string, value string) (string, error)
+
+	// GenerateParameterized generates one statement per SQLStatement with dialect placeholders
+	// instead of literals, together with the ordered arguments of each statement.
+	GenerateParameterized(model SQLData) ([]ParameterizedStatement, error)
+
+	// GetDialect returns the dialect the generator renders statements for.
+	GetDialect() Dialect
+
+	// ResolveLookups replaces the one-to-many lookups of the statements with the primary keys returned by resolver.
+	ResolveLookups(ctx context.Context, data *SQLData, resolver LookupResolver) error
 }
 
+// RawSQLMarker is the suggested RawSQLPrefix. Unlike a leading "=" it isn't taken for a formula
+// by spreadsheet applications.
+const RawSQLMarker = "sql:"
+
+// defaultTemplate is the insert template used when no custom template is configured.
+//
+//go:embed insert.tmpl
+var defaultTemplate string
+
 type Generator struct {
 	TemplatePath        string
+	TemplateFS          fs.FS
+	TemplateString      string
+	ExtraTemplateFuncs  template.FuncMap
 	ManyToManyDelimiter string
 	OneToManyDelimiter  string
 	Delimiter           string
@@ -39,24 +87,92 @@ type Generator struct {
 	ColumnsMapper       map[string]string
 	HashFunc            func(string) string
 	Adapter             AdapterInterface
+	Dialect             Dialect
+	RawSQLPrefix        string
+	RawSQLAllowlist     map[string]bool
+	DisableRawSQL       bool
+	MaxRowsPerStatement int
+	CollectErrors       bool
+	ColumnOrder         ColumnOrder
+	MissingValue        MissingValue
+	StrictLookups       bool
 }
 
-func NewGenerator(adapter AdapterInterface, columnsMapper map[string]string, delimiter string, arrayDelimiter string, oneToManyDelimiter string, manyToManyDelimiter string, hashFunc func(string) string) GeneratorInterface {
-	execPath, err := os.Executable()
-	if err != nil {
-		panic(err) // Handle the error appropriately
-	}
+// GeneratorConfig contains the settings of NewGeneratorWithConfig, NewSeeder fills it from SeederConfigInit.
+type GeneratorConfig struct {
+	ColumnsMapper       map[string]string
+	Delimiter           string
+	ArrayDelimiter      string
+	OneToManyDelimiter  string
+	ManyToManyDelimiter string
+	HashFunc            func(string) string
+	Dialect             Dialect          // optional - defaults to PostgresDialect
+	TemplatePath        string           // optional - path of a custom insert template
+	TemplateFS          fs.FS            // optional - file system TemplatePath is read from
+	TemplateString      string           // optional - custom insert template content
+	TemplateFuncs       template.FuncMap // optional - extra (or overriding) template functions
+	RawSQLPrefix        string           // optional - marks a cell value as a raw SQL expression (e.g. RawSQLMarker), unset by default
+	RawSQLAllowlist     []string         // optional - when set raw SQL may only call these functions
+	DisableRawSQL       bool             // optional - rejects raw SQL prefixes and !raw columns
+	MaxRowsPerStatement int              // optional - splits statements into chunks of at most this many rows
+	CollectErrors       bool             // optional - reports every invalid cell as CellErrors instead of the first one
+	ColumnOrder         ColumnOrder      // optional - defaults to the source order of the columns
+	MissingValue        MissingValue     // optional - value of the columns a row doesn't have, defaults to NULL
+	StrictLookups       bool             // optional - fails on lookup values that don't match any row
+}
 
-	tmpleatePath := fmt.Sprintf("%s/insert.tmpl", execPath)
-	return &Generator{
-		TemplatePath:        tmpleatePath, // Path to the SQL template file
-		Delimiter:           delimiter,
-		ManyToManyDelimiter: manyToManyDelimiter,
-		OneToManyDelimiter:  oneToManyDelimiter,
+// NewGenerator creates a Generator with the given delimiters and the default settings.
+// The dialect is the one of the adapter when it is an *Adapter, PostgresDialect otherwise.
+func NewGenerator(adapter AdapterInterface, columnsMapper map[string]string, delimiter string, arrayDelimiter string, oneToManyDelimiter string, manyToManyDelimiter string, hashFunc func(string) string) GeneratorInterface {
+	return NewGeneratorWithConfig(adapter, GeneratorConfig{
 		ColumnsMapper:       columnsMapper,
+		Delimiter:           delimiter,
 		ArrayDelimiter:      arrayDelimiter,
+		OneToManyDelimiter:  oneToManyDelimiter,
+		ManyToManyDelimiter: manyToManyDelimiter,
 		HashFunc:            hashFunc,
+	})
+}
+
+// NewGeneratorWithConfig creates a Generator from every GeneratorConfig setting.
+// A nil Dialect is the one of the adapter when it is an *Adapter, PostgresDialect otherwise.
+func NewGeneratorWithConfig(adapter AdapterInterface, config GeneratorConfig) GeneratorInterface {
+	if config.Dialect == nil {
+		if a, ok := adapter.(*Adapter); ok && a.Dialect != nil {
+			config.Dialect = a.Dialect
+		} else {
+			config.Dialect = PostgresDialect{}
+		}
+	}
+	var allowlist map[string]bool
+	if len(config.RawSQLAllowlist) > 0 {
+		allowlist = make(map[string]bool)
+		for _, function := range config.RawSQLAllowlist {
+			allowlist[strings.ToLower(function)] = true
+		}
+	}
+
+	return &Generator{
+		TemplatePath:        config.TemplatePath,
+		TemplateFS:          config.TemplateFS,
+		TemplateString:      config.TemplateString,
+		ExtraTemplateFuncs:  config.TemplateFuncs,
+		Delimiter:           config.Delimiter,
+		ManyToManyDelimiter: config.ManyToManyDelimiter,
+		OneToManyDelimiter:  config.OneToManyDelimiter,
+		ColumnsMapper:       config.ColumnsMapper,
+		ArrayDelimiter:      config.ArrayDelimiter,
+		HashFunc:            config.HashFunc,
 		Adapter:             adapter,
+		Dialect:             config.Dialect,
+		RawSQLPrefix:        config.RawSQLPrefix,
+		RawSQLAllowlist:     allowlist,
+		DisableRawSQL:       config.DisableRawSQL,
+		MaxRowsPerStatement: config.MaxRowsPerStatement,
+		CollectErrors:       config.CollectErrors,
+		ColumnOrder:         config.ColumnOrder,
+		MissingValue:        config.MissingValue,
+		StrictLookups:       config.StrictLookups,
 	}
 }
 
@@ -64,18 +180,50 @@ func NewGenerator(adapter AdapterInterface, columnsMapper map[string]string, del
 // It takes the column name, table name, and the value to search for.
 // If the value is "*", it selects the primary key from the related table without any WHERE clause.
 // Otherwise, it generates a subquery to select the primary key where the search key equals the provided value.
+// The value is always rendered as an escaped string literal of the generator dialect.
 func (g *Generator) GenerateOneToManySubquery(columnName string, tableName string, value string) (string, error) {
-	relation, err := g.Adapter.ParseOneToMany(columnName, tableName)
+	expr, err := g.GenerateOneToManyExpr(columnName, tableName, value)
 	if err != nil {
 		return "", err
 	}
-	if value == "null" || value == "NULL" || value == "EMPTY" || value == "empty" || value == "" {
-		return "NULL", nil
-	}
+	return g.Adapter.FormatValue(expr), nil
+}
 
-	cleaned := strings.TrimSpace(value)
-	return fmt.Sprintf("(SELECT %s FROM %s WHERE %s = '%s')", relation.PrimaryKey, relation.Table, relation.SearchKey, cleaned), nil
+// GenerateOneToManyExpr generates the lookup subquery of a one-to-many relationship column as an SQLExpr
+// with the search value as its argument, empty values and the NULL / EMPTY tokens return nil.
+// A one-to-many cell holds a single key so LookupWildcard is rejected, it only joins rows in many-to-many cells.
+func (g *Generator) GenerateOneToManyExpr(columnName string, tableName string, value string) (interface{}, error) {
+	relation, err := g.Adapter.ParseOneToMany(columnName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if isNullToken(value) || isEmptyToken(value) {
+		return nil, nil
+	}
+	if strings.TrimSpace(value) == LookupWildcard {
+		return nil, fmt.Errorf("the lookup wildcard '%s' selects every %s row, it can only be used in many-to-many cells", LookupWildcard, relation.Table)
+	}
 
+	searchKeys := relation.SearchKeys()
+	values := []string{value}
+	if len(searchKeys) > 1 {
+		values = strings.SplitN(value, CompositeKeyDelimiter, len(searchKeys))
+		if len(values) != len(searchKeys) {
+			return nil, fmt.Errorf("the lookup value '%s' has %d of the %d values of %s", value, len(values), len(searchKeys), relation.SearchKey)
+		}
+	}
+	expr := SQLExpr{
+		Fragments: []string{fmt.Sprintf("(SELECT %s FROM %s WHERE ",
+			g.Adapter.QuoteIdentifier(relation.PrimaryKey),
+			g.Adapter.QuoteIdentifier(relation.Table))},
+	}
+	for i, searchKey := range searchKeys {
+		expr.Fragments[i] += g.Adapter.QuoteIdentifier(searchKey) + " = "
+		expr.Fragments = append(expr.Fragments, " AND ")
+		expr.Args = append(expr.Args, strings.TrimSpace(values[i]))
+	}
+	expr.Fragments[len(expr.Fragments)-1] = ")"
+	return expr, nil
 }
 
 func (g *Generator) IsLastIndex(index int, a interface{}) bool {
@@ -97,6 +245,13 @@ func (g *Generator) GetColumnName(column string) string {
 		parts := strings.Split(column, "#")
 		return parts[0]
 	}
+	if g.Adapter.IsRawColumn(mappedColumnName) {
+		return strings.TrimSuffix(mappedColumnName, RawColumnSuffix)
+	}
+	if g.Adapter.IsEmbeddingColumn(mappedColumnName) {
+		parts := strings.Split(mappedColumnName, EmbeddingDelimiter)
+		return parts[0]
+	}
 
 	if g.Adapter.IsArrayColumn(mappedColumnName) {
 		return strings.TrimSuffix(mappedColumnName, "[]")
@@ -107,86 +262,428 @@ func (g *Generator) GetColumnName(column string) string {
 
 // GenerateRootTableDataRow generates a map representing a single row of data for root columns.
 // It handles one-to-many relationships by generating subqueries.
+// Plain values keep their loaded type (string, number, bool, nil, object, array) and are
+// rendered as SQL literals by Generate, lookups are stored as SQLExpr and delimited array cells as arrays.
+// Callers that used the returned values as SQL text render them with Adapter.FormatValue.
+// Invalid cells are reported as a *CellError, or as CellErrors of every invalid cell when CollectErrors is set.
 func (g *Generator) GenerateRootTableDataRow(rootColumns []string, row map[string]interface{}, tableName string) (map[string]interface{}, error) {
 	rootRow := make(map[string]interface{})
+	var errs CellErrors
 	for _, rootColumn := range rootColumns {
-		var err error
-		value := row[rootColumn].(string)
-		isOneToMany := g.Adapter.IsOneToMany(rootColumn)
-		isArrayColumn := g.Adapter.IsArrayColumn(rootColumn)
-		if isOneToMany {
-			value, err = g.GenerateOneToManySubquery(rootColumn, tableName, row[rootColumn].(string))
-			if err != nil {
-				return nil, err
+		cell, ok := row[rootColumn]
+		if !ok {
+			rootRow[rootColumn] = g.missingValue()
+			continue
+		}
+		value, err := g.rootValue(rootColumn, cell, tableName)
+		if err != nil {
+			cellErr := &CellError{Column: rootColumn, Err: err}
+			if !g.CollectErrors {
+				return nil, cellErr
 			}
-		} else if isArrayColumn {
-			value = g.FormatArrayValue(value)
+			errs = append(errs, cellErr)
+			continue
 		}
-
 		rootRow[rootColumn] = value
 	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
 
 	return rootRow, nil
 }
 
+// missingValue returns the value of a column the row doesn't have.
+func (g *Generator) missingValue() interface{} {
+	if g.MissingValue == MissingValueDefault {
+		return DefaultValue{}
+	}
+	return nil
+}
+
+// rootValue converts the cell of a root column to the value rendered by Generate.
+// The EMPTY token is the empty value of the column: an empty string for plain columns,
+// an empty array for array columns and NULL for lookups.
+// Hashed columns (e.g. password#) fail when no HashFunc is configured.
+func (g *Generator) rootValue(rootColumn string, value interface{}, tableName string) (interface{}, error) {
+	switch value.(type) {
+	case DefaultValue, OmittedValue:
+		return value, nil
+	}
+	if g.Adapter.IsOneToMany(rootColumn) {
+		return g.GenerateOneToManyExpr(rootColumn, tableName, g.StringValue(value))
+	}
+	if g.Adapter.IsArrayColumn(rootColumn) {
+		if str, ok := value.(string); ok {
+			return g.SplitArrayValue(str), nil
+		}
+		return value, nil
+	}
+	if str, ok := value.(string); ok && isEmptyToken(str) {
+		return RawSQL(g.Dialect.StringLiteral("")), nil
+	}
+	if g.Adapter.IsHashedColumn(rootColumn) {
+		// writing the plain value would leak the secrets the column is meant to hash
+		if g.HashFunc == nil {
+			return nil, fmt.Errorf("the column is hashed but no HashFunc is configured")
+		}
+		if str := g.StringValue(value); str != "" {
+			return g.HashFunc(str), nil
+		}
+		return value, nil
+	}
+	if str, ok := value.(string); ok && (g.Adapter.IsRawColumn(rootColumn) || g.isRawSQL(str)) {
+		return g.RawExpression(str)
+	}
+	return value, nil
+}
+
+// isRawSQL reports whether a cell value starts with RawSQLPrefix, no value does when it isn't set.
+func (g *Generator) isRawSQL(value string) bool {
+	return g.RawSQLPrefix != "" && strings.HasPrefix(value, g.RawSQLPrefix)
+}
+
+// RawExpression returns the raw SQL expression of a cell from a !raw column or prefixed with RawSQLPrefix.
+// When RawSQLAllowlist is set the expression may only call the listed functions.
+func (g *Generator) RawExpression(value string) (interface{}, error) {
+	expression := strings.TrimSpace(strings.TrimPrefix(value, g.RawSQLPrefix))
+	if expression == "" {
+		return nil, nil
+	}
+	if g.DisableRawSQL {
+		return nil, fmt.Errorf("raw SQL is disabled, found the raw expression '%s'", expression)
+	}
+	if g.RawSQLAllowlist != nil {
+		if err := validateRawSQL(expression, g.RawSQLAllowlist); err != nil {
+			return nil, fmt.Errorf("raw SQL '%s' is not allowed: %w", expression, err)
+		}
+	}
+	return RawSQL(expression), nil
+}
+
+// StringValue converts a typed cell value to the text used for lookups, hashing and splitting.
+// nil becomes an empty string.
+func (g *Generator) StringValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case RawSQL:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// SplitCellValues splits a many-to-many cell into its values.
+// Strings are split by the many-to-many row delimiter while JSON arrays are used as they are.
+func (g *Generator) SplitCellValues(value interface{}) []string {
+	if values, ok := value.([]interface{}); ok {
+		result := make([]string, len(values))
+		for i, item := range values {
+			result[i] = g.StringValue(item)
+		}
+		return result
+	}
+	return strings.Split(g.StringValue(value), g.Delimiter)
+}
+
 func (g *Generator) EscapeSQLString(s string) string {
 	return strings.ReplaceAll(s, "'", "''")
 }
 func (g *Generator) FormatArrayValue(value string) string {
-	if value == "" || value == "NULL" || value == "null" {
-		return "NULL"
+	return g.Adapter.FormatValue(g.SplitArrayValue(value))
+}
+
+// SplitArrayValue splits a delimited array cell into its trimmed items, empty cells and NULL tokens return nil
+// and the EMPTY token returns an empty array.
+func (g *Generator) SplitArrayValue(value string) interface{} {
+	if isNullToken(value) {
+		return nil
+	}
+	if isEmptyToken(value) {
+		return []interface{}{}
 	}
 	parts := strings.Split(value, g.ArrayDelimiter)
-	quotedParts := make([]string, len(parts))
+	items := make([]interface{}, len(parts))
 	for i, p := range parts {
-		cleaned := strings.TrimSpace(p)
-		escaped := g.EscapeSQLString(cleaned) // escape single quotes
-		quotedParts[i] = fmt.Sprintf("'%s'", escaped)
+		items[i] = strings.TrimSpace(p)
 	}
-	return fmt.Sprintf("ARRAY[%s]", strings.Join(quotedParts, ", "))
+	return items
+}
+
+// InsertClause returns the quoted table, column and conflict names of a statement.
+// On ConflictDoUpdate without explicit UpdateColumns every column outside the conflict target is updated.
+func (g *Generator) InsertClause(stmt SQLStatement) InsertClause {
+	columns := make([]string, len(stmt.Columns))
+	for i, column := range stmt.Columns {
+		columns[i] = g.Adapter.QuoteIdentifier(g.GetColumnName(column))
+	}
+	target := make([]string, len(stmt.Conflict.Target))
+	isTarget := make(map[string]bool)
+	for i, column := range stmt.Conflict.Target {
+		target[i] = g.Adapter.QuoteIdentifier(column)
+		isTarget[target[i]] = true
+	}
+	constraint := ""
+	if stmt.Conflict.Constraint != "" {
+		constraint = g.Adapter.QuoteIdentifier(stmt.Conflict.Constraint)
+	}
+	updateColumns := []string{}
+	if stmt.Conflict.Action == ConflictDoUpdate {
+		if len(stmt.Conflict.UpdateColumns) > 0 {
+			for _, column := range stmt.Conflict.UpdateColumns {
+				updateColumns = append(updateColumns, g.Adapter.QuoteIdentifier(column))
+			}
+		} else {
+			for _, column := range columns {
+				if !isTarget[column] {
+					updateColumns = append(updateColumns, column)
+				}
+			}
+		}
+	}
+	return InsertClause{
+		Table:              g.Adapter.QuoteIdentifier(g.Adapter.GetFullTableName(stmt.Schema, stmt.Table)),
+		Columns:            columns,
+		ConflictAction:     stmt.Conflict.Action,
+		ConflictTarget:     target,
+		ConflictConstraint: constraint,
+		UpdateColumns:      updateColumns,
+		Select:             stmt.From != "" || len(stmt.NotExists) > 0,
+		MatchColumns:       g.matchColumns(stmt, target),
+	}
+}
+
+// matchColumns returns the quoted columns identifying an existing row of a statement: the conflict target,
+// the statement Key or the primary key of the table (e.g. category_id for categories) when it is inserted.
+// It returns nil when the statement has none of them.
+func (g *Generator) matchColumns(stmt SQLStatement, target []string) []string {
+	if len(target) > 0 {
+		return target
+	}
+	if len(stmt.Key) > 0 {
+		key := make([]string, len(stmt.Key))
+		for i, column := range stmt.Key {
+			key[i] = g.Adapter.QuoteIdentifier(g.GetColumnName(column))
+		}
+		return key
+	}
+	primaryKey := g.Adapter.GetPrimaryKeyFromTableName(stmt.Table)
+	for _, column := range stmt.Columns {
+		if g.GetColumnName(column) == primaryKey {
+			return []string{g.Adapter.QuoteIdentifier(primaryKey)}
+		}
+	}
+	return nil
 }
 
 // GenerateTableData generates SQLData from a slice of maps.
-// It handles both root columns and many-to-many relationships.
+// The rows have no column order so the columns are sorted alphabetically,
+// use GenerateOrderedTableData to keep the order of the source.
 func (g *Generator) GenerateTableData(data []map[string]interface{}, schemaName string, tableName string) (*SQLData, error) {
+	return g.GenerateOrderedTableData(data, nil, schemaName, tableName)
+}
+
+// OrderColumns returns the union of the columns of every row in the order of columns, the remaining
+// columns are appended in the order of the first row they appear in (alphabetically within a row).
+// Every column is sorted alphabetically when ColumnOrder is ColumnOrderAlphabetical.
+func (g *Generator) OrderColumns(data []map[string]interface{}, columns []string) []string {
+	present := make(map[string]bool)
+	for _, row := range data {
+		for column := range row {
+			present[column] = true
+		}
+	}
+	ordered := make([]string, 0, len(present))
+	seen := make(map[string]bool, len(present))
+	if g.ColumnOrder == ColumnOrderSource {
+		for _, column := range columns {
+			if present[column] && !seen[column] {
+				ordered = append(ordered, column)
+				seen[column] = true
+			}
+		}
+	}
+	for _, row := range data {
+		remaining := make([]string, 0)
+		for column := range row {
+			if !seen[column] {
+				remaining = append(remaining, column)
+				seen[column] = true
+			}
+		}
+		sort.Strings(remaining)
+		ordered = append(ordered, remaining...)
+	}
+	if g.ColumnOrder == ColumnOrderAlphabetical {
+		sort.Strings(ordered)
+	}
+	return ordered
+}
+
+// GenerateOrderedTableData generates SQLData from a slice of maps where columns gives the source order
+// of the columns (e.g. the header of a sheet).
+// The statement has the columns of every row, a row without some of them gets NULL or DEFAULT depending on MissingValue.
+// It handles both root columns and many-to-many relationships, the join table statements follow
+// the order of their many-to-many columns.
+// Child rows columns (e.g. order_lines>order_number) hold arrays of objects generated as rows of the child table
+// after the parent rows, with a foreign key looking their parent row up.
+// Errors are reported as a *CellError holding the 1-based data row (0 for header errors) and the column,
+// or as CellErrors of every invalid header and cell when CollectErrors is set.
+// Empty many-to-many cells (or empty items of a cell) add no join rows while join rows
+// whose first search column is empty are reported as errors.
+func (g *Generator) GenerateOrderedTableData(data []map[string]interface{}, columns []string, schemaName string, tableName string) (*SQLData, error) {
 	if len(data) == 0 {
 		return nil, fmt.Errorf("empty data")
 	}
-	columnsStatemntParts := g.Adapter.SplitColumnsToStatemntParts(data[0])
+	columnsStatemntParts := g.Adapter.SplitOrderedColumnsToStatemntParts(g.OrderColumns(data, columns))
 	fullTableName := g.Adapter.GetFullTableName(schemaName, tableName)
-	manyToManyRelations, err := g.Adapter.ParseManyToManyColumns(columnsStatemntParts.ManyToManyColumns, schemaName, tableName)
-	if err != nil {
-		return nil, err
+
+	var errs CellErrors
+	// fail reports the error of a row (0 for the header), it returns the error to stop at
+	// unless every error is collected.
+	fail := func(err error, row int, column string) error {
+		located := cellErrors(err, row, column)
+		if !g.CollectErrors {
+			return located[0]
+		}
+		errs = append(errs, located...)
+		return nil
+	}
+
+	// headers are validated once so that a malformed header is reported a single time
+	validRootColumns := make([]string, 0, len(columnsStatemntParts.RootColumns))
+	for _, column := range columnsStatemntParts.RootColumns {
+		if g.Adapter.IsOneToMany(column) {
+			if _, err := g.Adapter.ParseOneToMany(column, fullTableName); err != nil {
+				if err := fail(err, 0, column); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		validRootColumns = append(validRootColumns, column)
+	}
+	manyToManyRelations := make(map[string]ManyToManyRelation)
+	manyToManyColumns := make([]string, 0, len(columnsStatemntParts.ManyToManyColumns))
+	for _, column := range columnsStatemntParts.ManyToManyColumns {
+		relation, err := g.Adapter.ParseManyToMany(column, schemaName, tableName)
+		if err != nil {
+			if err := fail(err, 0, column); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		manyToManyRelations[column] = relation
+		manyToManyColumns = append(manyToManyColumns, column)
 	}
+	childRelations := make(map[string]ChildRowsRelation)
+	childColumns := make([]string, 0, len(columnsStatemntParts.ChildRowsColumns))
+	for _, column := range columnsStatemntParts.ChildRowsColumns {
+		relation, err := g.Adapter.ParseChildRows(column, schemaName, tableName)
+		if err != nil {
+			if err := fail(err, 0, column); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		childRelations[column] = relation
+		childColumns = append(childColumns, column)
+	}
+
 	rootRows := make([]map[string]interface{}, 0)
 	manyToManyRows := make(map[string][]map[string]interface{})
-	for _, item := range data {
-
-		rootRow, err := g.GenerateRootTableDataRow(columnsStatemntParts.RootColumns, item, fullTableName)
+	// join rows of LookupWildcard items are inserted from the second table
+	wildcardRows := make(map[string][]map[string]interface{})
+	childRows := make(map[string][]map[string]interface{})
+	childOrigins := make(map[string][]childRowOrigin)
+	// the foreign key comes first followed by the keys of the child objects in the order they are first seen
+	childOrders := make(map[string][]string)
+	childSeen := make(map[string]map[string]bool)
+	for index, item := range data {
+		rowNumber := index + 1
+		rootRow, err := g.GenerateRootTableDataRow(validRootColumns, item, fullTableName)
 		if err != nil {
-			return nil, err
+			if err := fail(err, rowNumber, ""); err != nil {
+				return nil, err
+			}
+			continue
 		}
+		sourceLookups(rootRow, rowNumber, "")
 		rootRows = append(rootRows, rootRow)
-		for key, manyToManyColumn := range manyToManyRelations {
-			cellValue := item[key].(string)
-			cellValueRows := strings.Split(cellValue, g.Delimiter)
-			value1, err := g.GenerateOneToManySubquery(manyToManyColumn.Columns[0], manyToManyColumn.Table, item[manyToManyColumn.FirstSearchColumn].(string))
+		for _, key := range manyToManyColumns {
+			manyToManyColumn := manyToManyRelations[key]
+			cellValueRows := []string{}
+			for _, value := range g.SplitCellValues(item[key]) {
+				if strings.TrimSpace(value) != "" && !isEmptyToken(value) {
+					cellValueRows = append(cellValueRows, value)
+				}
+			}
+			if len(cellValueRows) == 0 {
+				continue
+			}
+			firstValue := g.StringValue(item[manyToManyColumn.FirstSearchColumn])
+			if strings.TrimSpace(firstValue) == "" {
+				err := fmt.Errorf("the '%s' value looking up the %s row is empty", manyToManyColumn.FirstSearchColumn, tableName)
+				if err := fail(err, rowNumber, key); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value1, err := g.GenerateOneToManyExpr(manyToManyColumn.Columns[0], manyToManyColumn.Table, firstValue)
 			if err != nil {
-				return nil, err
+				if err := fail(err, rowNumber, key); err != nil {
+					return nil, err
+				}
+				continue
 			}
 			for _, row := range cellValueRows {
-				value2, err := g.GenerateOneToManySubquery(manyToManyColumn.Columns[1], manyToManyColumn.SecondTable, row)
+				joinRow, wildcard, err := g.manyToManyRow(manyToManyColumn, value1, row)
 				if err != nil {
-					return nil, err
+					if err := fail(err, rowNumber, key); err != nil {
+						return nil, err
+					}
+					break
 				}
-
-				manyToManyRows[key] = append(manyToManyRows[key], map[string]interface{}{
-					manyToManyColumn.Columns[0]: value1,
-					manyToManyColumn.Columns[1]: value2,
-				})
+				sourceLookups(joinRow, rowNumber, key)
+				if wildcard {
+					wildcardRows[key] = append(wildcardRows[key], joinRow)
+					continue
+				}
+				manyToManyRows[key] = append(manyToManyRows[key], joinRow)
 			}
 
 		}
+		for _, key := range childColumns {
+			rows, columns, err := g.childRows(key, childRelations[key], item, tableName)
+			if err != nil {
+				if err := fail(err, rowNumber, key); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if childSeen[key] == nil {
+				childSeen[key] = map[string]bool{childRelations[key].Column: true}
+				childOrders[key] = []string{childRelations[key].Column}
+			}
+			for _, column := range columns {
+				if !childSeen[key][column] {
+					childSeen[key][column] = true
+					childOrders[key] = append(childOrders[key], column)
+				}
+			}
+			for i, row := range rows {
+				childRows[key] = append(childRows[key], row)
+				childOrigins[key] = append(childOrigins[key], childRowOrigin{row: rowNumber, index: i + 1})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
 	}
 	sqlData := SQLData{
 		Statements: []SQLStatement{
@@ -198,21 +695,221 @@ func (g *Generator) GenerateTableData(data []map[string]interface{}, schemaName
 			},
 		},
 	}
-	for key, rel := range manyToManyRelations {
-		sqlData.Statements = append(sqlData.Statements, SQLStatement{
-			Table:   rel.Table,
-			Schema:  "",
-			Columns: rel.Columns,
-			Rows:    manyToManyRows[key],
-		})
+	for _, key := range manyToManyColumns {
+		rel := manyToManyRelations[key]
+		// columns without values in any row get no statement
+		if len(manyToManyRows[key]) > 0 {
+			sqlData.Statements = append(sqlData.Statements, SQLStatement{
+				Table:   rel.Table,
+				Schema:  "",
+				Columns: rel.Columns,
+				Rows:    manyToManyRows[key],
+				Key:     rel.Columns[:2],
+			})
+		}
+		if len(wildcardRows[key]) > 0 {
+			sqlData.Statements = append(sqlData.Statements, SQLStatement{
+				Table:   rel.Table,
+				Columns: rel.Columns,
+				Rows:    wildcardRows[key],
+				From:    rel.SecondTable,
+				Key:     rel.Columns[:2],
+			})
+		}
+	}
+	// rows of auto-created parents are inserted before the statements looking them up
+	sqlData.Statements = append(g.autoCreateStatements(sqlData.Statements), sqlData.Statements...)
+	// child rows are inserted after their parents, with their own join, child and auto-created rows
+	for _, key := range childColumns {
+		if len(childRows[key]) == 0 {
+			continue
+		}
+		relation := childRelations[key]
+		childData, err := g.GenerateOrderedTableData(childRows[key], childOrders[key], relation.Schema, relation.Table)
+		if err != nil {
+			located := childRowsErrors(err, key, relation.Table, childOrigins[key])
+			if !g.CollectErrors {
+				return nil, located[0]
+			}
+			return nil, located
+		}
+		childLookupSources(childData.Statements, key, childOrigins[key])
+		sqlData.Statements = append(sqlData.Statements, childData.Statements...)
 	}
 
 	return &sqlData, nil
 }
 
-// Generate creates the SQL string from the provided SQLData using a template.
-func (g *Generator) Generate(data SQLData) (string, error) {
-	// Define the template functions.
+// sourceLookups sets the source cell of the lookups of a generated row, column is the header of the cell
+// the row was generated from or empty when the lookups come from the columns of the row.
+func sourceLookups(row map[string]interface{}, rowNumber int, column string) {
+	for key, value := range row {
+		if lookup, ok := value.(SQLExpr); ok && lookup.Source == nil {
+			cellColumn := column
+			if cellColumn == "" {
+				cellColumn = key
+			}
+			lookup.Source = &CellError{Row: rowNumber, Column: cellColumn}
+			row[key] = lookup
+		}
+	}
+}
+
+// childLookupSources moves the source cells of the lookups of child statements to the parent rows
+// holding the child rows, like childRowsErrors does for their errors.
+func childLookupSources(statements []SQLStatement, column string, origins []childRowOrigin) {
+	for _, stmt := range statements {
+		for _, row := range stmt.Rows {
+			for key, value := range row {
+				lookup, ok := value.(SQLExpr)
+				if !ok || lookup.Source == nil || lookup.Source.Row < 1 || lookup.Source.Row > len(origins) {
+					continue
+				}
+				lookup.Source = &CellError{Row: origins[lookup.Source.Row-1].row, Column: column}
+				row[key] = lookup
+			}
+		}
+	}
+}
+
+// childRowOrigin locates a child row at the 1-based parent row and item of the child rows array holding it.
+type childRowOrigin struct {
+	row   int
+	index int
+}
+
+// childRows returns copies of the rows of the child rows column of a parent row with the foreign key column
+// looking the parent row up.
+// The columns are the source order of the object keys for ChildRows cells, nil otherwise.
+// Empty cells have no child rows.
+func (g *Generator) childRows(column string, relation ChildRowsRelation, item map[string]interface{}, tableName string) ([]map[string]interface{}, []string, error) {
+	var (
+		items   []interface{}
+		columns []string
+	)
+	switch value := item[column].(type) {
+	case nil:
+		return nil, nil, nil
+	case []interface{}:
+		items = value
+	case ChildRows:
+		items = make([]interface{}, len(value.Rows))
+		for i, row := range value.Rows {
+			items[i] = row
+		}
+		columns = value.Columns
+	case string:
+		if strings.TrimSpace(value) == "" || isEmptyToken(value) || isNullToken(value) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("expected an array of %s rows, found '%s'", relation.Table, value)
+	default:
+		return nil, nil, fmt.Errorf("expected an array of %s rows, found %T", relation.Table, value)
+	}
+	if len(items) == 0 {
+		return nil, nil, nil
+	}
+	parentValue := g.StringValue(item[relation.ParentSearchColumn])
+	if strings.TrimSpace(parentValue) == "" {
+		return nil, nil, fmt.Errorf("the '%s' value looking up the %s row is empty", relation.ParentSearchColumn, tableName)
+	}
+	objects := make([]map[string]interface{}, len(items))
+	for i, childItem := range items {
+		object, ok := childItem.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("item %d of the %s rows is not an object", i+1, relation.Table)
+		}
+		objects[i] = object
+	}
+	rows := copyRows(objects)
+	for _, row := range rows {
+		row[relation.Column] = parentValue
+	}
+	return rows, columns, nil
+}
+
+// manyToManyRow generates the join row of a many-to-many cell item, the item holds the second table
+// search value followed by the values of the relation attributes (e.g. tag1:1:true).
+// Attributes missing from the item get NULL or DEFAULT depending on MissingValue.
+// A LookupWildcard search value joins every row of the second table, the returned row then selects
+// the second table primary key and has to be inserted from that table.
+func (g *Generator) manyToManyRow(relation ManyToManyRelation, firstValue interface{}, item string) (map[string]interface{}, bool, error) {
+	parts := g.splitAttributeValues(item, len(relation.Attributes))
+	search := strings.TrimSpace(parts[0])
+	wildcard := search == LookupWildcard
+	var secondValue interface{}
+	if wildcard {
+		lookup, err := g.Adapter.ParseOneToMany(relation.Columns[1], relation.SecondTable)
+		if err != nil {
+			return nil, false, err
+		}
+		secondValue = RawSQL(g.Adapter.QuoteIdentifier(lookup.PrimaryKey))
+	} else {
+		var err error
+		if secondValue, err = g.GenerateOneToManyExpr(relation.Columns[1], relation.SecondTable, search); err != nil {
+			return nil, false, err
+		}
+	}
+	joinRow := map[string]interface{}{
+		relation.Columns[0]: firstValue,
+		relation.Columns[1]: secondValue,
+	}
+	for i, attribute := range relation.Attributes {
+		if i+1 >= len(parts) {
+			joinRow[attribute] = g.missingValue()
+			continue
+		}
+		value, err := g.rootValue(attribute, attributeValue(strings.TrimSpace(parts[i+1])), relation.Table)
+		if err != nil {
+			return nil, false, fmt.Errorf("attribute '%s': %w", attribute, err)
+		}
+		joinRow[attribute] = value
+	}
+	return joinRow, wildcard, nil
+}
+
+// attributeNumberPattern matches the attribute values written as JSON numbers, values with
+// leading zeros (e.g. 007) are codes and stay text.
+var attributeNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// attributeValue types an attribute value of a many-to-many cell like a JSON cell: numbers become
+// json.Number and true / false booleans, other values stay text.
+func attributeValue(value string) interface{} {
+	if attributeNumberPattern.MatchString(value) {
+		return json.Number(value)
+	}
+	switch strings.ToLower(value) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
+
+// splitAttributeValues splits a many-to-many cell item into the search value and at most count attribute values,
+// the delimiter of the raw SQL prefix (e.g. sql:now()) is not treated as a separator.
+func (g *Generator) splitAttributeValues(item string, count int) []string {
+	parts := []string{}
+	rest := item
+	for len(parts) < count {
+		skip := 0
+		if len(parts) > 0 && g.RawSQLPrefix != "" && strings.HasPrefix(strings.TrimSpace(rest), g.RawSQLPrefix) {
+			skip = strings.Index(rest, g.RawSQLPrefix) + len(g.RawSQLPrefix)
+		}
+		index := strings.Index(rest[skip:], ManyToManyAttributeDelimiter)
+		if index < 0 {
+			break
+		}
+		parts = append(parts, rest[:skip+index])
+		rest = rest[skip+index+len(ManyToManyAttributeDelimiter):]
+	}
+	return append(parts, rest)
+}
+
+// TemplateFuncs returns the functions available to the insert template,
+// the TemplateFuncs provided in the config are added on top of them.
+func (g *Generator) TemplateFuncs() template.FuncMap {
 	funcMap := template.FuncMap{
 		"IsLastIndex":           g.IsLastIndex,
 		"GetFullTableName":      g.Adapter.GetFullTableName,
@@ -223,49 +920,252 @@ func (g *Generator) Generate(data SQLData) (string, error) {
 		"IsArrayColumn":         g.Adapter.IsArrayColumn,
 		"Escape":                g.EscapeSQLString,
 		"IsOneToMany":           g.Adapter.IsOneToMany,
+		"FormatValue":           g.Adapter.FormatValue,
+		"QuoteIdentifier":       g.Adapter.QuoteIdentifier,
+		"InsertClause":          g.InsertClause,
+		"InsertHeader": func(stmt SQLStatement) string {
+			return g.Dialect.InsertHeader(g.InsertClause(stmt))
+		},
+		"InsertFooter": func(stmt SQLStatement) string {
+			return g.Dialect.InsertFooter(g.InsertClause(stmt))
+		},
+		"LookupGuard": g.LookupGuard,
 	}
+	for name, fn := range g.ExtraTemplateFuncs {
+		funcMap[name] = fn
+	}
+	return funcMap
+}
 
-	// Read the SQL template from the template path.
-
-	templateContent := `
-{{- range $stmt := .Statements }}
-INSERT INTO {{ GetFullTableName $stmt.Schema $stmt.Table }} (
-  {{- range $index, $column := $stmt.Columns }} 
-  {{ GetColumnName $column }} {{- if not (IsLastIndex $index $stmt.Columns) }}, {{ end }}
-  {{- end }}
-) VALUES
-{{- range $rowIndex, $row := $stmt.Rows }}
-  (
-    {{- range $colIndex, $column := $stmt.Columns }}
-      {{- $value := index $row $column }}
-        {{- if IsHashedColumn $column }}
-          '{{ HashFunc $value }}' {{- if not (IsLastIndex $colIndex $stmt.Columns) }}, {{ end }}
-				{{- else if IsArrayColumn $column }}
-          {{ $value }} {{- if not (IsLastIndex $colIndex $stmt.Columns) }}, {{ end }}
-				{{- else if IsOneToMany $column }}
-				 {{ WraptWithSingleQuoute ($value) }} {{- if not (IsLastIndex $colIndex $stmt.Columns) }}, {{ end }}
-        {{- else }}
-				 {{ WraptWithSingleQuoute (Escape $value) }} {{- if not (IsLastIndex $colIndex $stmt.Columns) }}, {{ end }}
-        {{- end }}
-      {{- end }}
-  ) {{- if not (IsLastIndex $rowIndex $stmt.Rows) }}, {{ end }}
-{{- end }} ON CONFLICT  DO NOTHING;
-{{- end }}
-	`
-
-	// Parse the SQL template.
-	tmpl, err := template.New("sql").Funcs(funcMap).Parse(templateContent)
+// LoadTemplate returns the content of the insert template.
+// TemplateString takes precedence over TemplatePath which is read from TemplateFS when provided
+// and from the file system otherwise, the embedded insert.tmpl is used when none is set.
+func (g *Generator) LoadTemplate() (string, error) {
+	if g.TemplateString != "" {
+		return g.TemplateString, nil
+	}
+	if g.TemplatePath == "" {
+		return defaultTemplate, nil
+	}
+	var (
+		content []byte
+		err     error
+	)
+	if g.TemplateFS != nil {
+		content, err = fs.ReadFile(g.TemplateFS, g.TemplatePath)
+	} else {
+		content, err = os.ReadFile(g.TemplatePath)
+	}
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to read template '%s': %w", g.TemplatePath, err)
+	}
+	return string(content), nil
+}
+
+// SplitDefaults splits statements with OmittedValue values, and DefaultValue values when the dialect
+// doesn't support DEFAULT in VALUES rows, into consecutive statements of the rows leaving out
+// the same columns, where those columns are left out.
+func (g *Generator) SplitDefaults(data SQLData) SQLData {
+	split := SQLData{Statements: make([]SQLStatement, 0, len(data.Statements))}
+	for _, stmt := range data.Statements {
+		groupKey := ""
+		var group *SQLStatement
+		for _, row := range stmt.Rows {
+			columns := make([]string, 0, len(stmt.Columns))
+			defaulted := make([]string, 0)
+			for _, column := range stmt.Columns {
+				switch row[column].(type) {
+				case OmittedValue:
+					defaulted = append(defaulted, column)
+					continue
+				case DefaultValue:
+					if !g.Dialect.SupportsDefaultValues() {
+						defaulted = append(defaulted, column)
+						continue
+					}
+				}
+				columns = append(columns, column)
+			}
+			key := strings.Join(defaulted, "\x00")
+			if group == nil || key != groupKey {
+				split.Statements = append(split.Statements, stmt)
+				group = &split.Statements[len(split.Statements)-1]
+				group.Columns = columns
+				group.Rows = nil
+				groupKey = key
+			}
+			group.Rows = append(group.Rows, row)
+		}
+		if len(stmt.Rows) == 0 {
+			split.Statements = append(split.Statements, stmt)
+		}
+	}
+	return split
+}
+
+// ChunkStatements splits every statement with more than MaxRowsPerStatement rows into consecutive
+// statements of the same table holding at most MaxRowsPerStatement rows each.
+func (g *Generator) ChunkStatements(data SQLData) SQLData {
+	if g.MaxRowsPerStatement <= 0 {
+		return data
+	}
+	chunked := SQLData{Statements: make([]SQLStatement, 0, len(data.Statements))}
+	for _, stmt := range data.Statements {
+		if len(stmt.Rows) <= g.MaxRowsPerStatement {
+			chunked.Statements = append(chunked.Statements, stmt)
+			continue
+		}
+		for start := 0; start < len(stmt.Rows); start += g.MaxRowsPerStatement {
+			chunk := stmt
+			chunk.Rows = stmt.Rows[start:min(start+g.MaxRowsPerStatement, len(stmt.Rows))]
+			chunked.Statements = append(chunked.Statements, chunk)
+		}
+	}
+	return chunked
+}
+
+// LookupChecks returns a check for every distinct value the one-to-many columns of a statement look up,
+// raw values are not checked. With parameterized set the values are bound to
+// placeholders, they are written as literals otherwise.
+func (g *Generator) LookupChecks(stmt SQLStatement, parameterized bool) []LookupCheck {
+	var checks []LookupCheck
+	seen := make(map[string]bool)
+	g.forEachLookup([]SQLStatement{stmt}, func(relation OneToManyRelation, _ map[string]interface{}, column string, lookup SQLExpr, values []string) {
+		value := strings.Join(values, CompositeKeyDelimiter)
+		if seen[column+"\x00"+value] {
+			return
+		}
+		seen[column+"\x00"+value] = true
+
+		check := LookupCheck{Column: column, Table: relation.Table, SearchKey: relation.SearchKey, Value: value}
+		if lookup.Source != nil {
+			check.Location = lookup.Source.Location()
+		}
+		conditions := make([]string, len(lookup.Args))
+		for i, searchKey := range relation.SearchKeys() {
+			rendered := g.Adapter.FormatValue(lookup.Args[i])
+			if parameterized {
+				check.Args = append(check.Args, bindArgument(g.Dialect, lookup.Args[i]))
+				rendered = g.Dialect.Placeholder(len(check.Args))
+			}
+			conditions[i] = fmt.Sprintf("%s = %s", g.Adapter.QuoteIdentifier(searchKey), rendered)
+		}
+		check.SQL = fmt.Sprintf("SELECT 1 FROM %s WHERE %s", g.Adapter.QuoteIdentifier(relation.Table), strings.Join(conditions, " AND "))
+		checks = append(checks, check)
+	})
+	return checks
+}
+
+// LookupGuard renders the dialect guard written before a statement when StrictLookups is set,
+// it fails for dialects that can't check lookups in a script.
+func (g *Generator) LookupGuard(stmt SQLStatement) (string, error) {
+	if !g.StrictLookups {
+		return "", nil
+	}
+	checks := g.LookupChecks(stmt, false)
+	if len(checks) == 0 {
+		return "", nil
 	}
+	guardDialect, ok := g.Dialect.(LookupGuardDialect)
+	if !ok {
+		return "", fmt.Errorf("the %s dialect can't check lookups in generated SQL, run the seeds with Execute to check them against the database", g.Dialect.Name())
+	}
+	return guardDialect.LookupGuard(g.Adapter.GetFullTableName(stmt.Schema, stmt.Table), checks), nil
+}
+
+// GetDialect returns the dialect the generator renders statements for.
+func (g *Generator) GetDialect() Dialect {
+	return g.Dialect
+}
+
+// GenerateParameterized renders every statement (or chunk of MaxRowsPerStatement rows) on its own with
+// the insert template where values are bound to dialect placeholders ($1 / ? / @p1) instead of being written as literals.
+// Raw SQL expressions and NULLs are still written inline.
+// With StrictLookups the statements hold the checks of their lookup values instead of lookup guards.
+func (g *Generator) GenerateParameterized(data SQLData) ([]ParameterizedStatement, error) {
+	tmpl, err := g.ParseTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	data = g.ChunkStatements(g.SplitDefaults(data))
+	statements := make([]ParameterizedStatement, 0, len(data.Statements))
+	for _, stmt := range data.Statements {
+		var args []interface{}
+		var bind func(value interface{}) string
+		bind = func(value interface{}) string {
+			switch v := value.(type) {
+			case nil, RawSQL, DefaultValue, OmittedValue:
+				return g.Adapter.FormatValue(v)
+			case string:
+				if isNullToken(v) {
+					return "NULL"
+				}
+			case SQLExpr:
+				return v.Render(bind)
+			}
+			args = append(args, bindArgument(g.Dialect, value))
+			return g.Dialect.Placeholder(len(args))
+		}
+
+		var sqlBuffer bytes.Buffer
+		// lookups are checked by Execute against the database instead of guards in the statement
+		noGuard := func(SQLStatement) string { return "" }
+		stmtTmpl, err := tmpl.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone template: %w", err)
+		}
+		err = stmtTmpl.Funcs(template.FuncMap{"FormatValue": bind, "LookupGuard": noGuard}).Execute(&sqlBuffer, SQLData{Statements: []SQLStatement{stmt}})
+		if err != nil {
+			return nil, err
+		}
+		statement := ParameterizedStatement{
+			Schema: stmt.Schema,
+			Table:  stmt.Table,
+			SQL:    strings.TrimSpace(sqlBuffer.String()),
+			Args:   args,
+		}
+		if g.StrictLookups {
+			statement.Lookups = g.LookupChecks(stmt, true)
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
 
+// Generate creates the SQL string from the provided SQLData using a template,
+// statements are split into chunks of MaxRowsPerStatement rows when it is set
+// and by their defaulted columns when the dialect doesn't support DEFAULT in VALUES rows.
+func (g *Generator) Generate(data SQLData) (string, error) {
 	// Use a buffer to capture the generated SQL output.
 	var sqlBuffer bytes.Buffer
-	err = tmpl.Execute(&sqlBuffer, data)
-	if err != nil {
+	if err := g.GenerateTo(&sqlBuffer, data); err != nil {
 		return "", err
 	}
 
 	// Return the generated SQL as a string.
 	return sqlBuffer.String(), nil
 }
+
+// GenerateTo executes the template for the provided SQLData directly into w.
+func (g *Generator) GenerateTo(w io.Writer, data SQLData) error {
+	tmpl, err := g.ParseTemplate()
+	if err != nil {
+		return err
+	}
+	return g.GenerateWithTemplate(w, tmpl, data)
+}
+
+// ParseTemplate loads the insert template (see LoadTemplate) and parses it with TemplateFuncs.
+func (g *Generator) ParseTemplate() (*template.Template, error) {
+	templateContent, err := g.LoadTemplate()
+	if err != nil {
+		return nil, err
+	}
+	return template.New("sql").Funcs(g.TemplateFuncs()).Parse(templateContent)
+}
+
+// GenerateWithTemplate executes a template returned by ParseTemplate for the provided SQLData into w.
+func (g *Generator) GenerateWithTemplate(w io.Writer, tmpl *template.Template, data SQLData) error {
+	return tmpl.Execute(w, g.ChunkStatements(g.SplitDefaults(data)))
+}
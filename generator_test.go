@@ -1,9 +1,13 @@
 package sqlseeder
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"sort"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"text/template"
 
 	"github.com/stretchr/testify/require"
 )
@@ -64,13 +68,137 @@ func TestGenerator_GenerateRootTableDataRow(t *testing.T) {
 	}
 
 	expected := map[string]interface{}{
-		"id":                                     "'1'",
-		"name":                                   "'Product 1'",
-		"category_id**categories**category_name": "(SELECT category_id FROM categories WHERE category_name = 'Electronics')",
+		"id":                                     "1",
+		"name":                                   "Product 1",
+		"category_id**categories**category_name": lookupExpr("category_id", "categories", "category_name", "Electronics"),
 	}
 	require.Equal(t, result, expected)
 }
 
+func TestGenerator_GenerateRootTableDataRowTypedValues(t *testing.T) {
+	row := map[string]interface{}{
+		"price":                                  json.Number("12.5"),
+		"active":                                 true,
+		"notes":                                  nil,
+		"meta":                                   map[string]interface{}{"color": "red"},
+		"sizes":                                  []interface{}{"S", "M"},
+		"category_id**categories**category_name": json.Number("7"),
+	}
+	rootColumns := []string{"price", "active", "notes", "meta", "sizes", "category_id**categories**category_name"}
+
+	result, err := generator.GenerateRootTableDataRow(rootColumns, row, "products")
+	require.NoError(t, err)
+
+	rendered := make(map[string]string)
+	for column, value := range result {
+		rendered[column] = adapter.FormatValue(value)
+	}
+	require.Equal(t, map[string]string{
+		"price":                                  "12.5",
+		"active":                                 "TRUE",
+		"notes":                                  "NULL",
+		"meta":                                   `'{"color":"red"}'::jsonb`,
+		"sizes":                                  "ARRAY['S', 'M']",
+		"category_id**categories**category_name": "(SELECT category_id FROM categories WHERE category_name = '7')",
+	}, rendered)
+}
+
+func TestGenerator_GenerateRootTableDataRowHashedColumn(t *testing.T) {
+	row := map[string]interface{}{"email": "admin@example.com", "password#": "secret"}
+	rootColumns := []string{"email", "password#"}
+
+	_, err := generator.GenerateRootTableDataRow(rootColumns, row, "users")
+	var cellErr *CellError
+	require.True(t, errors.As(err, &cellErr))
+	require.Equal(t, "password#", cellErr.Column)
+	require.EqualError(t, err, "column 'password#': the column is hashed but no HashFunc is configured")
+
+	hashing := NewSeeder(SeederConfigInit{HashFunc: func(value string) string { return "hashed:" + value }}).GetGenerator()
+	result, err := hashing.GenerateRootTableDataRow(rootColumns, row, "users")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"email": "admin@example.com", "password#": "hashed:secret"}, result)
+}
+
+func TestGenerator_GenerateRootTableDataRowRawSQL(t *testing.T) {
+	row := map[string]interface{}{
+		"description":    "SELECT the best option",
+		"created_at":     "sql:now()",
+		"uuid!raw":       "gen_random_uuid()",
+		"deleted_at!raw": "",
+	}
+	rootColumns := []string{"description", "created_at", "uuid!raw", "deleted_at!raw"}
+
+	rawGenerator := NewSeeder(SeederConfigInit{RawSQLPrefix: RawSQLMarker}).GetGenerator()
+	result, err := rawGenerator.GenerateRootTableDataRow(rootColumns, row, "products")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"description":    "SELECT the best option",
+		"created_at":     RawSQL("now()"),
+		"uuid!raw":       RawSQL("gen_random_uuid()"),
+		"deleted_at!raw": nil,
+	}, result)
+	require.Equal(t, "'SELECT the best option'", adapter.FormatValue(result["description"]))
+	require.Equal(t, "uuid", generator.GetColumnName("uuid!raw"))
+
+	result, err = generator.GenerateRootTableDataRow([]string{"created_at"}, map[string]interface{}{"created_at": "sql:now()"}, "products")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"created_at": "sql:now()"}, result)
+
+	allowlisted := NewSeeder(SeederConfigInit{RawSQLPrefix: RawSQLMarker, RawSQLAllowlist: []string{"now"}}).GetGenerator()
+	_, err = allowlisted.GenerateRootTableDataRow([]string{"created_at"}, map[string]interface{}{"created_at": "sql:now()"}, "products")
+	require.NoError(t, err)
+	_, err = allowlisted.GenerateRootTableDataRow([]string{"created_at"}, map[string]interface{}{"created_at": "sql:pg_sleep(10)"}, "products")
+	require.EqualError(t, err, "column 'created_at': raw SQL 'pg_sleep(10)' is not allowed: function 'pg_sleep' is not in the raw SQL allowlist")
+
+	disabled := NewSeeder(SeederConfigInit{DisableRawSQL: true}).GetGenerator()
+	_, err = disabled.GenerateRootTableDataRow([]string{"uuid!raw"}, map[string]interface{}{"uuid!raw": "gen_random_uuid()"}, "products")
+	require.Error(t, err)
+}
+
+func TestGenerator_GenerateOneToManySubqueryEscapesValue(t *testing.T) {
+	result, err := generator.GenerateOneToManySubquery("category_id**categories**category_name", "products", "Kids' Toys")
+	require.NoError(t, err)
+	require.Equal(t, "(SELECT category_id FROM categories WHERE category_name = 'Kids'' Toys')", result)
+
+	result, err = generator.GenerateOneToManySubquery("category_id**categories**category_name", "products", "x' OR SELECT 1 --")
+	require.NoError(t, err)
+	require.Equal(t, "(SELECT category_id FROM categories WHERE category_name = 'x'' OR SELECT 1 --')", result)
+}
+
+// lookupExpr returns the one-to-many lookup expression expected for a cell value.
+func lookupExpr(primaryKey, table, searchKey, value string) SQLExpr {
+	return SQLExpr{
+		Fragments: []string{fmt.Sprintf("(SELECT %s FROM %s WHERE %s = ", primaryKey, table, searchKey), ")"},
+		Args:      []interface{}{value},
+	}
+}
+
+// sourcedLookup returns a lookup expression generated from the cell at the data row and column.
+func sourcedLookup(lookup SQLExpr, row int, column string) SQLExpr {
+	lookup.Source = &CellError{Row: row, Column: column}
+	return lookup
+}
+
+func TestGenerator_GenerateOneToManySubqueryComposite(t *testing.T) {
+	subquery, err := generator.GenerateOneToManySubquery("city_id**cities**country_code+city_name", "stores", " EG + Cairo ")
+	require.NoError(t, err)
+	require.Equal(t, "(SELECT city_id FROM cities WHERE country_code = 'EG' AND city_name = 'Cairo')", subquery)
+
+	// the last value keeps the rest of the cell
+	subquery, err = generator.GenerateOneToManySubquery("city_id**cities**country_code+city_name", "stores", "US+Salt+Lake")
+	require.NoError(t, err)
+	require.Equal(t, "(SELECT city_id FROM cities WHERE country_code = 'US' AND city_name = 'Salt+Lake')", subquery)
+
+	_, err = generator.GenerateOneToManySubquery("city_id**cities**country_code+city_name", "stores", "Cairo")
+	require.EqualError(t, err, "the lookup value 'Cairo' has 1 of the 2 values of country_code+city_name")
+}
+
+func TestGenerator_GenerateOneToManySubqueryWildcard(t *testing.T) {
+	// a one-to-many cell holds a single key, the wildcard only joins rows in many-to-many cells
+	_, err := generator.GenerateOneToManySubquery("category_id**categories**category_name", "products", " * ")
+	require.EqualError(t, err, "the lookup wildcard '*' selects every categories row, it can only be used in many-to-many cells")
+}
+
 func TestGenerator_GenerateTableData(t *testing.T) {
 	// Sample data
 	data := []map[string]interface{}{
@@ -97,14 +225,14 @@ func TestGenerator_GenerateTableData(t *testing.T) {
 				Columns: []string{"id", "product_name", "category_id**categories**category_name"},
 				Rows: []map[string]interface{}{
 					{
-						"id":                                     "'1'",
-						"product_name":                           "'Product 1'",
-						"category_id**categories**category_name": "(SELECT category_id FROM categories WHERE category_name = 'Electronics')",
+						"id":                                     "1",
+						"product_name":                           "Product 1",
+						"category_id**categories**category_name": sourcedLookup(lookupExpr("category_id", "categories", "category_name", "Electronics"), 1, "category_id**categories**category_name"),
 					},
 					{
-						"id":                                     "'2'",
-						"product_name":                           "'Product 2'",
-						"category_id**categories**category_name": "(SELECT category_id FROM categories WHERE category_name = 'Books')",
+						"id":                                     "2",
+						"product_name":                           "Product 2",
+						"category_id**categories**category_name": sourcedLookup(lookupExpr("category_id", "categories", "category_name", "Books"), 2, "category_id**categories**category_name"),
 					},
 				},
 			},
@@ -114,38 +242,32 @@ func TestGenerator_GenerateTableData(t *testing.T) {
 				Columns: []string{"product_id**public.products**product_name", "tag_id**tags**tag_name"},
 				Rows: []map[string]interface{}{
 					{
-						"product_id**public.products**product_name": "(SELECT product_id FROM public.products WHERE product_name = 'Product 1')",
-						"tag_id**tags**tag_name":                    "(SELECT tag_id FROM tags WHERE tag_name = 'tag1')",
+						"product_id**public.products**product_name": sourcedLookup(lookupExpr("product_id", "public.products", "product_name", "Product 1"), 1, "tag_id***product_tags***tags***tag_name***product_name"),
+						"tag_id**tags**tag_name":                    sourcedLookup(lookupExpr("tag_id", "tags", "tag_name", "tag1"), 1, "tag_id***product_tags***tags***tag_name***product_name"),
 					},
 					{
-						"product_id**public.products**product_name": "(SELECT product_id FROM public.products WHERE product_name = 'Product 1')",
-						"tag_id**tags**tag_name":                    "(SELECT tag_id FROM tags WHERE tag_name = 'tag2')",
+						"product_id**public.products**product_name": sourcedLookup(lookupExpr("product_id", "public.products", "product_name", "Product 1"), 1, "tag_id***product_tags***tags***tag_name***product_name"),
+						"tag_id**tags**tag_name":                    sourcedLookup(lookupExpr("tag_id", "tags", "tag_name", "tag2"), 1, "tag_id***product_tags***tags***tag_name***product_name"),
 					},
 					{
-						"product_id**public.products**product_name": "(SELECT product_id FROM public.products WHERE product_name = 'Product 2')",
-						"tag_id**tags**tag_name":                    "(SELECT tag_id FROM tags WHERE tag_name = 'tag3')",
+						"product_id**public.products**product_name": sourcedLookup(lookupExpr("product_id", "public.products", "product_name", "Product 2"), 2, "tag_id***product_tags***tags***tag_name***product_name"),
+						"tag_id**tags**tag_name":                    sourcedLookup(lookupExpr("tag_id", "tags", "tag_name", "tag3"), 2, "tag_id***product_tags***tags***tag_name***product_name"),
 					},
 				},
 			},
 		},
 	}
 
-	// Generate SQLData
-	result, err := generator.GenerateTableData(data, "public", "products")
+	// Generate SQLData in the order of the source header
+	header := []string{"id", "product_name", "category_id**categories**category_name", "tag_id***product_tags***tags***tag_name***product_name"}
+	result, err := generator.GenerateOrderedTableData(data, header, "public", "products")
 	require.NoError(t, err)
 
 	// Compare results
 	require.Equal(t, len(expected.Statements), len(result.Statements))
 
+	// rows follow the order of the data and their lookups the cells they come from
 	for i := range expected.Statements {
-		// Sort the Rows slice to ignore order when comparing
-		sort.Slice(expected.Statements[i].Rows, func(j, k int) bool {
-			return fmt.Sprint(expected.Statements[i].Rows[j]) < fmt.Sprint(expected.Statements[i].Rows[k])
-		})
-		sort.Slice(result.Statements[i].Rows, func(j, k int) bool {
-			return fmt.Sprint(result.Statements[i].Rows[j]) < fmt.Sprint(result.Statements[i].Rows[k])
-		})
-
 		require.Equal(t, expected.Statements[i].Table, result.Statements[i].Table)
 		require.Equal(t, expected.Statements[i].Schema, result.Statements[i].Schema)
 		require.Equal(t, expected.Statements[i].Columns, result.Statements[i].Columns)
@@ -154,5 +276,113 @@ func TestGenerator_GenerateTableData(t *testing.T) {
 }
 
 func TestGenerator_Generate(t *testing.T) {
-	// ... (This test requires reading from a template file and generating SQL, so it's also best to implement based on your specific template and logic) ...
+	data := SQLData{
+		Statements: []SQLStatement{
+			{
+				Schema:  "public",
+				Table:   "products",
+				Columns: []string{"product_name", "category_id**categories**category_name"},
+				Rows: []map[string]interface{}{
+					{"product_name": "Laptop", "category_id**categories**category_name": lookupExpr("category_id", "categories", "category_name", "Electronics")},
+					{"product_name": "Phone", "category_id**categories**category_name": RawSQL("NULL")},
+				},
+			},
+		},
+	}
+
+	result, err := generator.Generate(data)
+	require.NoError(t, err)
+	require.Equal(t,
+		"INSERT INTO public.products (product_name, category_id) VALUES ( 'Laptop', (SELECT category_id FROM categories WHERE category_name = 'Electronics') ), ( 'Phone', NULL ) ON CONFLICT DO NOTHING;",
+		strings.Join(strings.Fields(result), " "))
+}
+
+func TestGenerator_GenerateCustomTemplate(t *testing.T) {
+	data := SQLData{Statements: []SQLStatement{{Table: "tags", Columns: []string{"tag_name"}, Rows: []map[string]interface{}{{"tag_name": "new"}}}}}
+	funcs := template.FuncMap{"Audit": func() string { return "-- seeded by tests" }}
+	templateContent := `{{ Audit }}{{ range .Statements }} {{ InsertHeader . }}{{ range .Rows }} ({{ FormatValue (index . "tag_name") }}){{ end }};{{ end }}`
+
+	fromString := NewSeeder(SeederConfigInit{TemplateString: templateContent, TemplateFuncs: funcs})
+	result, err := fromString.GetGenerator().Generate(data)
+	require.NoError(t, err)
+	require.Equal(t, "-- seeded by tests INSERT INTO tags (tag_name) VALUES ('new');", result)
+
+	fromFS := NewSeeder(SeederConfigInit{
+		TemplatePath:  "templates/insert.tmpl",
+		TemplateFS:    fstest.MapFS{"templates/insert.tmpl": {Data: []byte(templateContent)}},
+		TemplateFuncs: funcs,
+	})
+	result, err = fromFS.GetGenerator().Generate(data)
+	require.NoError(t, err)
+	require.Equal(t, "-- seeded by tests INSERT INTO tags (tag_name) VALUES ('new');", result)
+
+	missing := NewSeeder(SeederConfigInit{TemplatePath: "missing.tmpl"})
+	_, err = missing.GetGenerator().Generate(data)
+	require.Error(t, err)
+}
+
+func TestGenerator_GenerateParameterized(t *testing.T) {
+	data := SQLData{
+		Statements: []SQLStatement{
+			{
+				Schema:  "public",
+				Table:   "products",
+				Columns: []string{"product_name", "price", "tags[]", "created_at", "category_id**categories**category_name"},
+				Rows: []map[string]interface{}{
+					{"product_name": "Kid's", "price": json.Number("12"), "tags[]": []interface{}{"a", "b"}, "created_at": RawSQL("now()"), "category_id**categories**category_name": lookupExpr("category_id", "categories", "category_name", "Toys")},
+					{"product_name": "NULL", "price": nil, "tags[]": nil, "created_at": RawSQL("now()"), "category_id**categories**category_name": nil},
+				},
+			},
+			{Table: "tags", Columns: []string{"tag_name"}, Rows: []map[string]interface{}{{"tag_name": "new"}}},
+		},
+	}
+	testCases := []struct {
+		dialect  Dialect
+		expected []string
+		args     [][]interface{}
+	}{
+		{
+			PostgresDialect{},
+			[]string{
+				"INSERT INTO public.products (product_name, price, tags, created_at, category_id) VALUES ( $1, $2, $3, now(), (SELECT category_id FROM categories WHERE category_name = $4) ), ( NULL, NULL, NULL, now(), NULL ) ON CONFLICT DO NOTHING;",
+				"INSERT INTO tags (tag_name) VALUES ( $1 ) ON CONFLICT DO NOTHING;",
+			},
+			[][]interface{}{{"Kid's", int64(12), `{"a","b"}`, "Toys"}, {"new"}},
+		},
+		{
+			MySQLDialect{},
+			[]string{
+				"INSERT IGNORE INTO public.products (product_name, price, tags, created_at, category_id) VALUES ( ?, ?, ?, now(), (SELECT category_id FROM categories WHERE category_name = ?) ), ( NULL, NULL, NULL, now(), NULL );",
+				"INSERT IGNORE INTO tags (tag_name) VALUES ( ? );",
+			},
+			[][]interface{}{{"Kid's", int64(12), `["a","b"]`, "Toys"}, {"new"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.dialect.Name(), func(t *testing.T) {
+			dialectSeeder := NewSeeder(SeederConfigInit{Dialect: tc.dialect})
+			result, err := dialectSeeder.GetGenerator().GenerateParameterized(data)
+			require.NoError(t, err)
+			require.Len(t, result, len(tc.expected))
+			for i, statement := range result {
+				require.Equal(t, tc.expected[i], strings.Join(strings.Fields(statement.SQL), " "))
+				require.Equal(t, tc.args[i], statement.Args)
+			}
+			require.Equal(t, "public", result[0].Schema)
+			require.Equal(t, "products", result[0].Table)
+		})
+	}
+}
+
+func TestGenerator_OrderColumns(t *testing.T) {
+	data := []map[string]interface{}{{"price": 1, "name": "a", "id": 1, "notes": ""}}
+	require.Equal(t, []string{"name", "price", "id", "notes"}, generator.(*Generator).OrderColumns(data, []string{"name", "missing", "price"}))
+	require.Equal(t, []string{"id", "name", "notes", "price"}, generator.(*Generator).OrderColumns(data, nil))
+
+	alphabetical := NewSeeder(SeederConfigInit{ColumnOrder: ColumnOrderAlphabetical}).GetGenerator().(*Generator)
+	require.Equal(t, []string{"id", "name", "notes", "price"}, alphabetical.OrderColumns(data, []string{"name", "price"}))
+
+	// columns of later rows are appended in the order they are first seen
+	data = append(data, map[string]interface{}{"sku": "x", "name": "b"}, map[string]interface{}{"color": "red", "brand": "acme"})
+	require.Equal(t, []string{"name", "price", "id", "notes", "sku", "brand", "color"}, generator.(*Generator).OrderColumns(data, []string{"name", "price"}))
 }
@@ -0,0 +1,497 @@
+package sqlseeder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// defaultLookupBatchSize is the number of values DatabaseResolver looks up per query when BatchSize is not set.
+const defaultLookupBatchSize = 500
+
+// LookupRequest holds the distinct values one-to-many columns look up in a table by the same search keys.
+type LookupRequest struct {
+	Table      string
+	PrimaryKey string
+	SearchKeys []string
+	Values     [][]string // distinct values with one item per search key
+}
+
+// LookupResolver resolves lookup values to the primary keys of their rows before the SQL is generated,
+// so that statements hold literal ids instead of one subquery per cell.
+type LookupResolver interface {
+	// ResolveLookups returns the primary key of every value of the request in the same order,
+	// nil for the values it can't resolve which keep their lookup subquery.
+	ResolveLookups(ctx context.Context, request LookupRequest) ([]interface{}, error)
+}
+
+// lookupResolvers asks every resolver in turn for the values the previous ones didn't resolve.
+type lookupResolvers []LookupResolver
+
+func (r lookupResolvers) ResolveLookups(ctx context.Context, request LookupRequest) ([]interface{}, error) {
+	ids := make([]interface{}, len(request.Values))
+	pending := make([]int, len(request.Values))
+	for i := range pending {
+		pending[i] = i
+	}
+	for _, resolver := range r {
+		if len(pending) == 0 {
+			break
+		}
+		subRequest := request
+		subRequest.Values = make([][]string, len(pending))
+		for i, index := range pending {
+			subRequest.Values[i] = request.Values[index]
+		}
+		resolved, err := resolver.ResolveLookups(ctx, subRequest)
+		if err != nil {
+			return nil, err
+		}
+		if len(resolved) != len(pending) {
+			return nil, fmt.Errorf("lookup resolver returned %d ids for %d values of %s", len(resolved), len(pending), request.Table)
+		}
+		unresolved := pending[:0]
+		for i, index := range pending {
+			if resolved[i] == nil {
+				unresolved = append(unresolved, index)
+				continue
+			}
+			ids[index] = resolved[i]
+		}
+		pending = unresolved
+	}
+	return ids, nil
+}
+
+// forEachLookup calls fn for every lookup with search values in the one-to-many columns of the statements,
+// values holds the text of the search values. Values that are not lookups (raw SQL, NULL) are skipped.
+func (g *Generator) forEachLookup(statements []SQLStatement, fn func(relation OneToManyRelation, row map[string]interface{}, column string, lookup SQLExpr, values []string)) {
+	for _, stmt := range statements {
+		for _, column := range stmt.Columns {
+			if !g.Adapter.IsOneToMany(column) {
+				continue
+			}
+			relation, err := g.Adapter.ParseOneToMany(column, stmt.Table)
+			if err != nil {
+				continue
+			}
+			searchKeys := relation.SearchKeys()
+			for _, row := range stmt.Rows {
+				lookup, ok := row[column].(SQLExpr)
+				if !ok || len(lookup.Args) != len(searchKeys) {
+					continue
+				}
+				values := make([]string, len(lookup.Args))
+				for i, arg := range lookup.Args {
+					values[i] = g.StringValue(arg)
+				}
+				fn(relation, row, column, lookup, values)
+			}
+		}
+	}
+}
+
+// ResolveLookups replaces the one-to-many lookups of the statements with the primary keys returned by
+// resolver, the values of every looked up table are resolved at once.
+func (g *Generator) ResolveLookups(ctx context.Context, data *SQLData, resolver LookupResolver) error {
+	type lookupGroup struct {
+		request LookupRequest
+		index   map[string]int
+		ids     []interface{}
+	}
+	groups := make(map[string]*lookupGroup)
+	var order []string
+	// visit calls fn with the group and the value index of every lookup of the statements
+	visit := func(fn func(group *lookupGroup, row map[string]interface{}, column string, index int)) {
+		g.forEachLookup(data.Statements, func(relation OneToManyRelation, row map[string]interface{}, column string, _ SQLExpr, values []string) {
+			groupKey := strings.Join([]string{relation.Table, relation.PrimaryKey, relation.SearchKey}, "\x00")
+			group, ok := groups[groupKey]
+			if !ok {
+				group = &lookupGroup{
+					request: LookupRequest{Table: relation.Table, PrimaryKey: relation.PrimaryKey, SearchKeys: relation.SearchKeys()},
+					index:   make(map[string]int),
+				}
+				groups[groupKey] = group
+				order = append(order, groupKey)
+			}
+			valueKey := strings.Join(values, "\x00")
+			index, ok := group.index[valueKey]
+			if !ok {
+				index = len(group.request.Values)
+				group.index[valueKey] = index
+				group.request.Values = append(group.request.Values, values)
+			}
+			fn(group, row, column, index)
+		})
+	}
+
+	visit(func(*lookupGroup, map[string]interface{}, string, int) {})
+	for _, groupKey := range order {
+		group := groups[groupKey]
+		ids, err := resolver.ResolveLookups(ctx, group.request)
+		if err != nil {
+			return fmt.Errorf("failed to resolve lookups of %s: %w", group.request.Table, err)
+		}
+		if len(ids) != len(group.request.Values) {
+			return fmt.Errorf("lookup resolver returned %d ids for %d values of %s", len(ids), len(group.request.Values), group.request.Table)
+		}
+		group.ids = ids
+	}
+	visit(func(group *lookupGroup, row map[string]interface{}, column string, index int) {
+		if index < len(group.ids) && group.ids[index] != nil {
+			row[column] = group.ids[index]
+		}
+	})
+	return nil
+}
+
+// autoCreateStatements returns the statements inserting the rows looked up by the AutoCreate relations of
+// the statements, one per looked up table with the distinct search values in the order they are first seen.
+// Rows are inserted with INSERT ... SELECT ... WHERE NOT EXISTS so that existing rows are skipped without
+// a unique key on the search columns, values inserted by the statements themselves (a table looking up
+// its own rows) are not created.
+func (g *Generator) autoCreateStatements(statements []SQLStatement) []SQLStatement {
+	var created []SQLStatement
+	positions := make(map[string]int)
+	seen := make(map[string]bool)
+	inserted := func(relation OneToManyRelation, values []string) bool {
+		for _, stmt := range statements {
+			if stmt.Table != relation.Table && g.Adapter.GetFullTableName(stmt.Schema, stmt.Table) != relation.Table {
+				continue
+			}
+			if g.insertsValues(stmt, relation.SearchKeys(), values) {
+				return true
+			}
+		}
+		return false
+	}
+	g.forEachLookup(statements, func(relation OneToManyRelation, _ map[string]interface{}, _ string, lookup SQLExpr, values []string) {
+		if !relation.AutoCreate {
+			return
+		}
+		tableKey := relation.Table + "\x00" + relation.SearchKey
+		valueKey := tableKey + "\x00" + strings.Join(values, "\x00")
+		if seen[valueKey] {
+			return
+		}
+		seen[valueKey] = true
+		if inserted(relation, values) {
+			return
+		}
+		position, ok := positions[tableKey]
+		if !ok {
+			position = len(created)
+			positions[tableKey] = position
+			created = append(created, SQLStatement{
+				Table:     relation.Table,
+				Columns:   relation.SearchKeys(),
+				Conflict:  ConflictConfig{Action: ConflictError},
+				NotExists: relation.SearchKeys(),
+			})
+		}
+		row := make(map[string]interface{}, len(lookup.Args))
+		for i, searchKey := range relation.SearchKeys() {
+			row[searchKey] = lookup.Args[i]
+		}
+		created[position].Rows = append(created[position].Rows, row)
+	})
+	return created
+}
+
+// insertsValues reports whether a row of stmt has the values of the columns named columns (by GetColumnName).
+func (g *Generator) insertsValues(stmt SQLStatement, columns []string, values []string) bool {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		for _, header := range stmt.Columns {
+			if !g.Adapter.IsOneToMany(header) && g.GetColumnName(header) == column {
+				headers[i] = header
+				break
+			}
+		}
+		if headers[i] == "" {
+			return false
+		}
+	}
+	for _, row := range stmt.Rows {
+		matches := true
+		for i, header := range headers {
+			if g.StringValue(row[header]) != values[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// DatabaseResolver resolves lookups with one query per looked up table and batch of values,
+// the resolved ids are cached for the lifetime of the resolver.
+// Values are matched by their text, values the database compares differently (e.g. with a case
+// insensitive collation) are left unresolved and keep their subquery.
+type DatabaseResolver struct {
+	DB        SQLQueryer
+	Dialect   Dialect
+	BatchSize int // values per query, defaults to 500
+
+	adapter AdapterInterface
+	mu      sync.Mutex
+	cache   map[string]interface{}
+}
+
+// NewDatabaseResolver returns a resolver that looks up the ids in db, dialect defaults to PostgresDialect.
+func NewDatabaseResolver(db SQLQueryer, dialect Dialect) *DatabaseResolver {
+	if dialect == nil {
+		dialect = PostgresDialect{}
+	}
+	return &DatabaseResolver{DB: db, Dialect: dialect}
+}
+
+func (r *DatabaseResolver) ResolveLookups(ctx context.Context, request LookupRequest) ([]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cache == nil {
+		r.cache = make(map[string]interface{})
+		r.adapter = NewAdapter("", "", WithDialect(r.Dialect))
+	}
+	prefix := strings.Join([]string{request.Table, request.PrimaryKey, strings.Join(request.SearchKeys, CompositeKeyDelimiter)}, "\x00") + "\x00"
+
+	var missing [][]string
+	for _, values := range request.Values {
+		if _, ok := r.cache[prefix+strings.Join(values, "\x00")]; !ok {
+			missing = append(missing, values)
+		}
+	}
+	batchSize := r.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultLookupBatchSize
+	}
+	for start := 0; start < len(missing); start += batchSize {
+		if err := r.query(ctx, request, missing[start:min(start+batchSize, len(missing))], prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]interface{}, len(request.Values))
+	for i, values := range request.Values {
+		ids[i] = r.cache[prefix+strings.Join(values, "\x00")]
+	}
+	return ids, nil
+}
+
+// query looks up a batch of values and caches the ids found.
+func (r *DatabaseResolver) query(ctx context.Context, request LookupRequest, values [][]string, prefix string) error {
+	columns := []string{r.adapter.QuoteIdentifier(request.PrimaryKey)}
+	for _, searchKey := range request.SearchKeys {
+		columns = append(columns, r.adapter.QuoteIdentifier(searchKey))
+	}
+	var args []interface{}
+	placeholder := func(value string) string {
+		args = append(args, value)
+		return r.Dialect.Placeholder(len(args))
+	}
+	var condition string
+	if len(request.SearchKeys) == 1 {
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = placeholder(value[0])
+		}
+		condition = fmt.Sprintf("%s IN (%s)", columns[1], strings.Join(placeholders, ", "))
+	} else {
+		conditions := make([]string, len(values))
+		for i, value := range values {
+			parts := make([]string, len(value))
+			for j, item := range value {
+				parts[j] = fmt.Sprintf("%s = %s", columns[j+1], placeholder(item))
+			}
+			conditions[i] = fmt.Sprintf("(%s)", strings.Join(parts, " AND "))
+		}
+		condition = strings.Join(conditions, " OR ")
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s", strings.Join(columns, ", "), r.adapter.QuoteIdentifier(request.Table), condition)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		scanned := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range scanned {
+			dest[i] = &scanned[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		id := scanned[0]
+		if bytes, ok := id.([]byte); ok {
+			id = string(bytes)
+		}
+		if id == nil {
+			continue
+		}
+		keys := make([]string, len(scanned)-1)
+		for i, value := range scanned[1:] {
+			if bytes, ok := value.([]byte); ok {
+				value = string(bytes)
+			}
+			keys[i] = fmt.Sprint(value)
+		}
+		key := prefix + strings.Join(keys, "\x00")
+		// the first row wins when the search keys are not unique
+		if _, ok := r.cache[key]; !ok {
+			r.cache[key] = id
+		}
+	}
+	return rows.Err()
+}
+
+// seededRows keeps the ids of the rows seeded with their primary key during one Seed, SeedAll, SeedTo,
+// SeedParameterized or Execute call so that the lookups of the tables seeded after them can use them
+// without a database. Only the search columns looked up in the call are kept, indexed by their values,
+// so the memory grows with the seeded rows times the search column sets looked up in their table.
+type seededRows struct {
+	generator GeneratorInterface
+	adapter   AdapterInterface
+	tables    map[string][]*seededIndex // indexes by looked up table name
+}
+
+// seededIndex maps the joined search values of the seeded rows of a table to their ids.
+type seededIndex struct {
+	searchKeys []string
+	ids        map[string]interface{}
+}
+
+// need registers a lookup so that the search values of the rows recorded after it are kept.
+func (r *seededRows) need(relation OneToManyRelation) {
+	searchKeys := relation.SearchKeys()
+	for _, index := range r.tables[relation.Table] {
+		if strings.Join(index.searchKeys, "\x00") == strings.Join(searchKeys, "\x00") {
+			return
+		}
+	}
+	if r.tables == nil {
+		r.tables = make(map[string][]*seededIndex)
+	}
+	r.tables[relation.Table] = append(r.tables[relation.Table], &seededIndex{searchKeys: searchKeys, ids: make(map[string]interface{})})
+}
+
+// needColumns registers the lookups of the one-to-many, many-to-many and child rows columns found in
+// the rows of a table, so that the tables seeded before it keep the values it looks up.
+// Invalid headers are skipped, the generator reports them.
+func (r *seededRows) needColumns(schemaName string, tableName string, data []map[string]interface{}) {
+	columns := make(map[string]bool)
+	for _, row := range data {
+		for column := range row {
+			columns[column] = true
+		}
+	}
+	for column := range columns {
+		switch {
+		case r.adapter.IsOneToMany(column):
+			if relation, err := r.adapter.ParseOneToMany(column, tableName); err == nil {
+				r.need(relation)
+			}
+		case r.adapter.IsManyToMany(column):
+			if relation, err := r.adapter.ParseManyToMany(column, schemaName, tableName); err == nil {
+				if lookup, err := r.adapter.ParseOneToMany(relation.Columns[1], relation.Table); err == nil {
+					r.need(lookup)
+				}
+			}
+		case r.adapter.IsChildRows(column):
+			if relation, err := r.adapter.ParseChildRows(column, schemaName, tableName); err == nil {
+				r.needColumns(relation.Schema, relation.Table, childRowsOf(data, column))
+			}
+		}
+	}
+}
+
+// record registers the lookups of the statements and then keeps the search values of the rows of the
+// root statements that have the primary key of their table, by full table name and by table name.
+func (r *seededRows) record(statements []SQLStatement) {
+	for _, stmt := range statements {
+		for _, column := range stmt.Columns {
+			if !r.adapter.IsOneToMany(column) {
+				continue
+			}
+			if relation, err := r.adapter.ParseOneToMany(column, stmt.Table); err == nil {
+				r.need(relation)
+			}
+		}
+	}
+	for _, stmt := range statements {
+		if stmt.From != "" {
+			continue
+		}
+		fullName := r.adapter.GetFullTableName(stmt.Schema, stmt.Table)
+		indexes := r.tables[fullName]
+		if fullName != stmt.Table {
+			indexes = append(indexes[:len(indexes):len(indexes)], r.tables[stmt.Table]...)
+		}
+		if len(indexes) == 0 {
+			continue
+		}
+		primaryKey := r.adapter.GetPrimaryKeyFromTableName(stmt.Table)
+		columns := make(map[string]string, len(stmt.Columns))
+		for _, column := range stmt.Columns {
+			if name := r.generator.GetColumnName(column); columns[name] == "" {
+				columns[name] = column
+			}
+		}
+		for _, row := range stmt.Rows {
+			id, ok := r.value(row, columns[primaryKey])
+			if !ok {
+				continue
+			}
+		indexes:
+			for _, index := range indexes {
+				keys := make([]string, len(index.searchKeys))
+				for i, searchKey := range index.searchKeys {
+					value, ok := r.value(row, columns[searchKey])
+					if !ok {
+						continue indexes
+					}
+					keys[i] = r.generator.StringValue(value)
+				}
+				key := strings.Join(keys, "\x00")
+				// the first row wins when the search values are not unique
+				if _, ok := index.ids[key]; !ok {
+					index.ids[key] = id
+				}
+			}
+		}
+	}
+}
+
+// value returns the literal value of a column of a row, NULL values and expressions are not seeded values.
+func (r *seededRows) value(row map[string]interface{}, column string) (interface{}, bool) {
+	if column == "" {
+		return nil, false
+	}
+	switch value := row[column].(type) {
+	case nil, RawSQL, SQLExpr, DefaultValue, OmittedValue:
+		return nil, false
+	case string:
+		if isNullToken(value) {
+			return nil, false
+		}
+	}
+	return row[column], true
+}
+
+func (r *seededRows) ResolveLookups(ctx context.Context, request LookupRequest) ([]interface{}, error) {
+	ids := make([]interface{}, len(request.Values))
+	for _, index := range r.tables[request.Table] {
+		if strings.Join(index.searchKeys, "\x00") != strings.Join(request.SearchKeys, "\x00") {
+			continue
+		}
+		for i, values := range request.Values {
+			ids[i] = index.ids[strings.Join(values, "\x00")]
+		}
+	}
+	return ids, nil
+}
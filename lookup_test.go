@@ -0,0 +1,142 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mapResolver resolves the values found in ids and records the requests it receives.
+type mapResolver struct {
+	ids      map[string]interface{}
+	requests []LookupRequest
+}
+
+func (r *mapResolver) ResolveLookups(ctx context.Context, request LookupRequest) ([]interface{}, error) {
+	r.requests = append(r.requests, request)
+	ids := make([]interface{}, len(request.Values))
+	for i, values := range request.Values {
+		ids[i] = r.ids[strings.Join(values, CompositeKeyDelimiter)]
+	}
+	return ids, nil
+}
+
+func TestSeeder_ResolveSeededLookups(t *testing.T) {
+	resolver := &mapResolver{ids: map[string]interface{}{"Toys": 7}}
+	resolvingSeeder := NewSeeder(SeederConfigInit{ResolveSeededLookups: true, LookupResolver: resolver})
+
+	result, err := resolvingSeeder.SeedAll([]SeederConfig{
+		jsonConfig("products", `[
+			{"product_name": "Laptop", "category_id**categories**category_name": "Electronics"},
+			{"product_name": "Robot", "category_id**categories**category_name": "Toys"},
+			{"product_name": "Novel", "category_id**categories**category_name": "Books"},
+			{"product_name": "Mouse", "category_id**categories**category_name": "Electronics"}
+		]`),
+		jsonConfig("categories", `[{"category_id": 1, "category_name": "Electronics"}, {"category_name": "Books"}]`),
+	})
+	require.NoError(t, err)
+	result = strings.Join(strings.Fields(result), " ")
+	require.Contains(t, result, "( 'Laptop', 1 ), ( 'Robot', 7 ), ( 'Novel', (SELECT category_id FROM categories WHERE category_name = 'Books') ), ( 'Mouse', 1 )")
+
+	// the resolver only gets the values the seeded rows don't resolve, once per table
+	require.Equal(t, []LookupRequest{{
+		Table:      "categories",
+		PrimaryKey: "category_id",
+		SearchKeys: []string{"category_name"},
+		Values:     [][]string{{"Toys"}, {"Books"}},
+	}}, resolver.requests)
+}
+
+func TestSeeder_ResolveSeededLookupsScope(t *testing.T) {
+	resolvingSeeder := NewSeeder(SeederConfigInit{ResolveSeededLookups: true})
+	products := jsonConfig("products", `[{"product_name": "Laptop", "category_id**categories**category_name": "Electronics"}]`)
+
+	// rows are only kept by the call that seeded them
+	_, err := resolvingSeeder.Seed(jsonConfig("categories", `[{"category_id": 1, "category_name": "Electronics"}]`))
+	require.NoError(t, err)
+	result, err := resolvingSeeder.Seed(products)
+	require.NoError(t, err)
+	require.Contains(t, result, "(SELECT category_id FROM categories WHERE category_name = 'Electronics')")
+
+	// SeedTo keeps them across its batches
+	var script bytes.Buffer
+	err = NewSeeder(SeederConfigInit{ResolveSeededLookups: true, MaxRowsPerStatement: 1}).SeedTo(&script, SeederConfig{
+		Loader:     CSVLoader{Content: *bytes.NewBufferString("category_id,category_name,parent_id**categories**category_name\n1,Electronics,\n2,Laptops,Electronics\n")},
+		SchemaName: "public",
+		TableName:  "categories",
+	})
+	require.NoError(t, err)
+	require.Contains(t, strings.Join(strings.Fields(script.String()), " "), "( '2', 'Laptops', '1' )")
+
+	// only the ids and the looked up search values are kept
+	seeded := resolvingSeeder.(*Seeder).newSeededRows([]SeederConfig{products}, []*Dataset{{Rows: []map[string]interface{}{
+		{"product_name": "Laptop", "category_id**categories**category_name": "Electronics"},
+	}}})
+	seeded.record([]SQLStatement{{
+		Schema:  "public",
+		Table:   "categories",
+		Columns: []string{"category_id", "category_name", "description"},
+		Rows: []map[string]interface{}{
+			{"category_id": 1, "category_name": "Electronics", "description": "Devices"},
+			{"category_name": "Books", "description": "Novels"},
+		},
+	}})
+	require.Len(t, seeded.tables, 1)
+	require.Equal(t, []*seededIndex{{searchKeys: []string{"category_name"}, ids: map[string]interface{}{"Electronics": 1}}}, seeded.tables["categories"])
+}
+
+// countingQueryer counts the queries run on a database.
+type countingQueryer struct {
+	*sql.DB
+	queries int
+}
+
+func (q *countingQueryer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	q.queries++
+	return q.DB.QueryContext(ctx, query, args...)
+}
+
+func TestDatabaseResolver(t *testing.T) {
+	db := newTestDatabase(t)
+	_, err := db.Exec(`
+		INSERT INTO categories (category_id, category_name) VALUES (10, 'Electronics'), (20, 'Books');
+		CREATE TABLE cities (city_id INTEGER PRIMARY KEY, country_code TEXT, city_name TEXT);
+		INSERT INTO cities (city_id, country_code, city_name) VALUES (1, 'EG', 'Cairo'), (2, 'US', 'Cairo');
+	`)
+	require.NoError(t, err)
+	queryer := &countingQueryer{DB: db}
+	resolver := NewDatabaseResolver(queryer, SQLiteDialect{})
+	resolver.BatchSize = 1
+	resolvingSeeder := NewSeeder(SeederConfigInit{Dialect: SQLiteDialect{}, LookupResolver: resolver})
+
+	config := sqliteConfig("products", `[
+		{"product_name": "Laptop", "category_id**categories**category_name": "Electronics"},
+		{"product_name": "Novel", "category_id**categories**category_name": "Books"},
+		{"product_name": "Robot", "category_id**categories**category_name": "Toys"},
+		{"product_name": "Mouse", "category_id**categories**category_name": "Electronics"}
+	]`)
+	statements, err := resolvingSeeder.SeedParameterized(config)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"Laptop", int64(10), "Novel", int64(20), "Robot", "Toys", "Mouse", int64(10)}, statements[0].Args)
+	require.Contains(t, statements[0].SQL, "(SELECT category_id FROM categories WHERE category_name = ?)")
+	// one query per batch of values
+	require.Equal(t, 3, queryer.queries)
+
+	// resolved ids are cached, only the unresolved value is looked up again
+	_, err = resolvingSeeder.SeedParameterized(config)
+	require.NoError(t, err)
+	require.Equal(t, 4, queryer.queries)
+
+	ids, err := NewDatabaseResolver(db, SQLiteDialect{}).ResolveLookups(context.Background(), LookupRequest{
+		Table:      "cities",
+		PrimaryKey: "city_id",
+		SearchKeys: []string{"country_code", "city_name"},
+		Values:     [][]string{{"US", "Cairo"}, {"EG", "Giza"}, {"EG", "Cairo"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(2), nil, int64(1)}, ids)
+}
@@ -1,11 +1,150 @@
 package sqlseeder
 
-// SQLStatement represents an individual SQL statement with multiple rows of data
-type SQLStatement struct {
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RawSQL is an already rendered SQL fragment (a raw expression, a vector literal, NULL)
+// that is written to the generated statement verbatim.
+type RawSQL string
+
+// SQLExpr is an SQL expression with arguments, e.g. a lookup subquery.
+// Fragments holds the SQL around the arguments (len(Args)+1 items), each argument is rendered
+// as a literal by Generate and bound to a placeholder by GenerateParameterized.
+type SQLExpr struct {
+	Fragments []string
+	Args      []interface{}
+	// Source is the cell the lookup was generated from, lookup checks report it for missing values.
+	Source *CellError
+}
+
+// Render joins the fragments with the rendered arguments.
+func (e SQLExpr) Render(render func(arg interface{}) string) string {
+	var builder strings.Builder
+	for i, fragment := range e.Fragments {
+		builder.WriteString(fragment)
+		if i < len(e.Args) {
+			builder.WriteString(render(e.Args[i]))
+		}
+	}
+	return builder.String()
+}
+
+// ParameterizedStatement is a statement with dialect placeholders and its ordered arguments.
+type ParameterizedStatement struct {
 	Schema  string
 	Table   string
-	Columns []string
-	Rows    []map[string]interface{}
+	SQL     string
+	Args    []interface{}
+	Lookups []LookupCheck // checked by Execute before the statement runs when StrictLookups is set
+}
+
+// LookupCheck is a query returning a row when a value looked up by a statement column exists.
+type LookupCheck struct {
+	Column    string // header of the statement column, e.g. category_id**categories**category_name
+	Table     string // looked up table
+	SearchKey string // search columns joined by CompositeKeyDelimiter
+	Value     string // looked up value, composite values are joined by CompositeKeyDelimiter
+	Location  string // source cell of the first row looking the value up, e.g. excel products!C3
+	SQL       string
+	Args      []interface{}
+}
+
+// String describes the looked up row and where it is looked up,
+// e.g. categories.category_name = 'Books' (excel products!C3).
+func (c LookupCheck) String() string {
+	description := fmt.Sprintf("%s.%s = '%s'", c.Table, c.SearchKey, c.Value)
+	if c.Location != "" {
+		description += fmt.Sprintf(" (%s)", c.Location)
+	}
+	return description
+}
+
+// ColumnOrder controls the order of the columns in the generated statements.
+type ColumnOrder int
+
+const (
+	// ColumnOrderSource keeps the header order of sheets and CSV files and the key order of JSON objects,
+	// columns without a known source order are sorted alphabetically.
+	ColumnOrderSource ColumnOrder = iota
+	// ColumnOrderAlphabetical sorts the columns by name.
+	ColumnOrderAlphabetical
+)
+
+// DefaultValue is the value of a column left to its column default, it is rendered as DEFAULT
+// or the column is left out of the statement when the dialect doesn't support DEFAULT in VALUES rows.
+type DefaultValue struct{}
+
+// OmittedValue is the value of a column left out of the statement for its row,
+// statements are split so that the rows omitting the same columns are inserted together.
+type OmittedValue struct{}
+
+// BlankPolicy controls the value of blank cells.
+type BlankPolicy int
+
+const (
+	// BlankNull inserts NULL for blank cells (the default).
+	BlankNull BlankPolicy = iota
+	// BlankDefault leaves blank cells to their column default with DEFAULT.
+	BlankDefault
+	// BlankEmpty handles blank cells like the EMPTY token: an empty string for plain columns,
+	// an empty array for array columns and NULL for lookups.
+	BlankEmpty
+	// BlankOmit leaves the column out of the statement for the rows where it is blank.
+	BlankOmit
+)
+
+// MissingValue controls the value of columns a row doesn't have.
+type MissingValue int
+
+const (
+	// MissingValueNull inserts NULL in columns missing from a row (the default).
+	MissingValueNull MissingValue = iota
+	// MissingValueDefault leaves columns missing from a row to their column default.
+	MissingValueDefault
+)
+
+// ConflictAction controls what happens when an inserted row conflicts with an existing one.
+type ConflictAction int
+
+const (
+	// ConflictDoNothing skips conflicting rows (the default).
+	ConflictDoNothing ConflictAction = iota
+	// ConflictDoUpdate updates the conflicting rows with the inserted values.
+	ConflictDoUpdate
+	// ConflictError emits a plain insert so conflicts fail the statement.
+	ConflictError
+)
+
+// ConflictConfig describes the conflict behavior of a table insert.
+type ConflictConfig struct {
+	Action ConflictAction
+	// Target lists the columns of the unique key used to detect conflicts.
+	Target []string
+	// Constraint is the unique constraint used to detect conflicts (PostgreSQL only), used instead of Target.
+	Constraint string
+	// UpdateColumns lists the columns set from the inserted values on ConflictDoUpdate,
+	// defaults to every inserted column that is not part of Target.
+	UpdateColumns []string
+}
+
+// SQLStatement represents an individual SQL statement with multiple rows of data
+type SQLStatement struct {
+	Schema   string
+	Table    string
+	Columns  []string
+	Rows     []map[string]interface{}
+	Conflict ConflictConfig
+	From     string // when set every row is written as SELECT <values> FROM <From> instead of a VALUES row
+	// Key lists the columns (entries of Columns) identifying an existing row when Conflict has no Target,
+	// e.g. the foreign keys of join rows. Only dialects matching rows themselves (the SQL Server MERGE) use it.
+	Key []string
+	// NotExists lists the columns (entries of Columns) comparing every row with the rows of the table,
+	// each row is written as SELECT <values> WHERE NOT EXISTS (...) and is only inserted when no row of the
+	// table has the same values, e.g. the parents created for lookups.
+	NotExists []string
 }
 type ManyToManyRelation struct {
 	Table              string
@@ -14,16 +153,54 @@ type ManyToManyRelation struct {
 	FirstSearchColumn  string
 	SecondSearchColumn string
 	Columns            []string
+	Attributes         []string // extra join table columns filled from the cell values, also listed in Columns
 }
 type OneToManyRelation struct {
 	Table      string
 	PrimaryKey string
 	ForeignKey string
-	SearchKey  string
+	SearchKey  string // search columns joined by CompositeKeyDelimiter for composite lookups
+	AutoCreate bool   // missing rows are inserted into Table before the lookup
+}
+
+// SearchKeys returns the search columns of the relation, composite lookups have more than one.
+func (r OneToManyRelation) SearchKeys() []string {
+	return strings.Split(r.SearchKey, CompositeKeyDelimiter)
+}
+
+// ChildRows holds the objects of a child rows cell with their keys in source order, JsonLoader decodes
+// child rows arrays into it so that the child table columns follow the order of the objects.
+// A plain []interface{} of objects works too, its columns are then sorted.
+type ChildRows struct {
+	Rows    []map[string]interface{}
+	Columns []string
+}
+
+// MarshalJSON writes the rows as the array they were loaded from, e.g. for function configs.
+func (c ChildRows) MarshalJSON() ([]byte, error) {
+	if c.Rows == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c.Rows)
+}
+
+// ChildRowsRelation describes a column holding the rows of a child table.
+type ChildRowsRelation struct {
+	Schema             string // schema of the child table, the parent schema unless the header names one
+	Table              string
+	ForeignKey         string
+	ParentSearchColumn string
+	Column             string // one-to-many header of the foreign key looking the parent row up
+}
+
+type EmbeddingRelation struct {
+	Column       string
+	SourceColumn string
 }
 type ColumnsStatemntParts struct {
 	RootColumns       []string
 	ManyToManyColumns []string
+	ChildRowsColumns  []string
 }
 
 // SQLData represents all SQL statements to be executed
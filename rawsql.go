@@ -0,0 +1,116 @@
+package sqlseeder
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// validateRawSQL checks that a raw SQL expression only calls allowlisted functions.
+// In allowlist mode an expression must be a function call whose arguments are numbers,
+// quoted strings or other allowlisted calls, e.g. now(), gen_random_uuid() or date_trunc('day', now()).
+func validateRawSQL(expression string, allowlist map[string]bool) error {
+	parser := rawSQLParser{input: []rune(expression), allowlist: allowlist}
+	parser.skipSpaces()
+	if err := parser.parseCall(); err != nil {
+		return err
+	}
+	parser.skipSpaces()
+	if parser.pos != len(parser.input) {
+		return fmt.Errorf("unexpected '%s' after function call", string(parser.input[parser.pos:]))
+	}
+	return nil
+}
+
+type rawSQLParser struct {
+	input     []rune
+	pos       int
+	allowlist map[string]bool
+}
+
+func (p *rawSQLParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *rawSQLParser) peek() rune {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// parseCall parses <name>(<argument>, ...) where name is allowlisted.
+func (p *rawSQLParser) parseCall() error {
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] == '_' || p.input[p.pos] == '.' || unicode.IsLetter(p.input[p.pos]) || unicode.IsDigit(p.input[p.pos])) {
+		p.pos++
+	}
+	name := string(p.input[start:p.pos])
+	if name == "" {
+		return fmt.Errorf("expected a function call")
+	}
+	if !p.allowlist[strings.ToLower(name)] {
+		return fmt.Errorf("function '%s' is not in the raw SQL allowlist", name)
+	}
+	p.skipSpaces()
+	if p.peek() != '(' {
+		return fmt.Errorf("expected '(' after '%s'", name)
+	}
+	p.pos++
+	p.skipSpaces()
+	if p.peek() == ')' {
+		p.pos++
+		return nil
+	}
+	for {
+		if err := p.parseArgument(); err != nil {
+			return err
+		}
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+			p.skipSpaces()
+		case ')':
+			p.pos++
+			return nil
+		default:
+			return fmt.Errorf("expected ',' or ')' in arguments of '%s'", name)
+		}
+	}
+}
+
+// parseArgument parses a number, a quoted string or a nested call.
+func (p *rawSQLParser) parseArgument() error {
+	r := p.peek()
+	switch {
+	case r == '\'':
+		p.pos++
+		for p.pos < len(p.input) {
+			if p.input[p.pos] == '\'' {
+				if p.pos+1 < len(p.input) && p.input[p.pos+1] == '\'' {
+					p.pos += 2
+					continue
+				}
+				p.pos++
+				return nil
+			}
+			p.pos++
+		}
+		return fmt.Errorf("unterminated string literal")
+	case r == '-' || r == '.' || unicode.IsDigit(r):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.input) && (p.input[p.pos] == '.' || unicode.IsDigit(p.input[p.pos])) {
+			p.pos++
+		}
+		if strings.Trim(string(p.input[start:p.pos]), "-.") == "" {
+			return fmt.Errorf("invalid number '%s'", string(p.input[start:p.pos]))
+		}
+		return nil
+	default:
+		return p.parseCall()
+	}
+}
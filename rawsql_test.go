@@ -0,0 +1,36 @@
+package sqlseeder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRawSQL(t *testing.T) {
+	allowlist := map[string]bool{"now": true, "gen_random_uuid": true, "date_trunc": true}
+	testCases := []struct {
+		expression string
+		valid      bool
+	}{
+		{"now()", true},
+		{" gen_random_uuid ( ) ", true},
+		{"date_trunc('day', now())", true},
+		{"date_trunc('it''s', -1.5)", true},
+		{"NOW()", true},
+		{"pg_sleep(10)", false},
+		{"now(); DROP TABLE users", false},
+		{"now() -- comment", false},
+		{"date_trunc('day', (SELECT password FROM users))", false},
+		{"date_trunc('day", false},
+		{"now", false},
+		{"", false},
+	}
+	for _, tc := range testCases {
+		err := validateRawSQL(tc.expression, allowlist)
+		if tc.valid {
+			require.NoError(t, err, tc.expression)
+		} else {
+			require.Error(t, err, tc.expression)
+		}
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"strings"
+	"text/template"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -15,22 +18,90 @@ type DataLoader interface {
 	Load() ([]map[string]interface{}, error)
 }
 
+// Source kinds reported by the built-in loaders.
+const (
+	SourceExcel = "excel"
+	SourceCSV   = "csv"
+	SourceJSON  = "json"
+)
+
+// Dataset holds loaded rows together with where they come from, it is used to report
+// errors with their sheet, row and cell coordinates.
+type Dataset struct {
+	Rows     []map[string]interface{}
+	Columns  []string // column names in source order
+	Source   string   // kind of source (SourceExcel, SourceCSV, SourceJSON), empty when unknown
+	Sheet    string   // sheet name of Excel sources
+	FirstRow int      // 1-based source row of the first row, 2 for sheets, the line after the header for CSV
+	// RowNumbers holds the 1-based source row of each row when they don't follow each other,
+	// e.g. the lines CSV records start on, FirstRow then only locates header errors.
+	RowNumbers []int
+
+	// offset is the number of source rows read before Rows, set on the batches of SeedTo
+	offset int
+}
+
+// DatasetLoader is implemented by loaders that describe where their rows come from.
+type DatasetLoader interface {
+	DataLoader
+	LoadDataset() (*Dataset, error)
+}
+
+// loadDataset loads the rows of a loader, loaders that aren't DatasetLoaders get a dataset without source.
+func loadDataset(loader DataLoader) (*Dataset, error) {
+	if datasetLoader, ok := loader.(DatasetLoader); ok {
+		return datasetLoader.LoadDataset()
+	}
+	data, err := loader.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Dataset{Rows: data, FirstRow: 1}, nil
+}
+
 // JsonLoader loads data from JSON
 type JsonLoader struct {
 	Content bytes.Buffer
+	// Reader is read instead of Content when set (e.g. an opened file), so that SeedTo decodes
+	// large files while reading them. A reader can only be loaded once.
+	Reader io.Reader
 }
 
 // ExcelLoader loads data from Excel
 type ExcelLoader struct {
 	Content       bytes.Buffer
+	Reader        io.Reader // optional - read instead of Content when set, e.g. an opened file
 	SheetName     string
 	ColumnsMapper map[string]string
 }
 
-// CSVLoader loads data from CSV (example for future extensibility)
-type CSVLoader struct {
+// WorkbookLoader loads every sheet (or the listed Sheets) of an Excel workbook, one table per sheet
+type WorkbookLoader struct {
 	Content       bytes.Buffer
+	Sheets        []string          // optional - defaults to every sheet of the workbook
+	SheetTables   map[string]string // optional - sheet name to "schema.table" or "table"
+	SchemaName    string            // optional - schema of the tables named without one
+	ColumnsMapper map[string]string
+}
+
+// MemoryLoader serves rows that are already loaded
+type MemoryLoader struct {
+	Rows []map[string]interface{}
+}
+
+// CSVLoader loads data from CSV
+type CSVLoader struct {
+	Content bytes.Buffer
+	// Reader is read instead of Content when set (e.g. an opened file), so that SeedTo parses
+	// large files while reading them. A reader can only be loaded once.
+	Reader        io.Reader
 	ColumnsMapper map[string]string
+	Separator     rune // optional - defaults to ','
+	Quote         rune // optional - defaults to '"'
+	KeepBOM       bool // optional - a leading UTF-8 BOM is stripped unless set
+	// AllowRaggedRows pads short rows with empty cells and drops cells past the
+	// header instead of failing on rows whose length differs from the header.
+	AllowRaggedRows bool
 }
 
 // SeederConfig contains all seeding configuration
@@ -39,22 +110,61 @@ type SeederConfig struct {
 	SchemaName   string // optional - required for table-based insert
 	TableName    string // optional - required for table-based insert
 	FunctionName string // optional - if provided, uses function-based import
+	// Conflict controls how rows that already exist are handled, defaults to skipping them.
+	Conflict ConflictConfig
+	Context  context.Context // optional - passed to Embed / EmbedBulk, defaults to context.Background()
+	// Blanks sets the value of blank cells, ColumnBlanks overrides it for the listed columns
+	// (keyed by column name, e.g. created_at, or by header). Blank many-to-many cells add no join rows.
+	Blanks       BlankPolicy
+	ColumnBlanks map[string]BlankPolicy
 }
 
 // Load implementation for JsonLoader
 func (j JsonLoader) Load() ([]map[string]interface{}, error) {
+	dataset, err := j.LoadDataset()
+	if err != nil {
+		return nil, err
+	}
+	return dataset.Rows, nil
+}
+
+// LoadDataset implementation for JsonLoader, rows are numbered from 1 in the order of the array
+// and the columns follow the order in which the keys first appear.
+func (j JsonLoader) LoadDataset() (*Dataset, error) {
+	rows, err := j.Stream()
+	if err != nil {
+		return nil, err
+	}
 	var data []map[string]interface{}
-	if err := json.Unmarshal(j.Content.Bytes(), &data); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, row)
 	}
-	return data, nil
+	dataset := iteratorSource(rows)
+	dataset.Rows = data
+	return &dataset, nil
 }
 
 // Load implementation for ExcelLoader
 func (e ExcelLoader) Load() ([]map[string]interface{}, error) {
-	f, err := excelize.OpenReader(&e.Content)
+	dataset, err := e.LoadDataset()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open Excel file: %w", err)
+		return nil, err
+	}
+	return dataset.Rows, nil
+}
+
+// LoadDataset implementation for ExcelLoader
+func (e ExcelLoader) LoadDataset() (*Dataset, error) {
+	f, err := e.open()
+	if err != nil {
+		return nil, err
 	}
 	defer func() {
 		if err := f.Close(); err != nil {
@@ -62,37 +172,183 @@ func (e ExcelLoader) Load() ([]map[string]interface{}, error) {
 		}
 	}()
 
-	rows, err := f.GetRows(e.SheetName)
+	return readSheet(f, e.SheetName, e.ColumnsMapper)
+}
+
+// readSheet reads the rows of a sheet keyed by the normalized header row.
+func readSheet(f *excelize.File, sheetName string, columnsMapper map[string]string) (*Dataset, error) {
+	rows, err := f.GetRows(sheetName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sheet '%s': %w", e.SheetName, err)
+		return nil, fmt.Errorf("failed to get sheet '%s': %w", sheetName, err)
 	}
 
 	if len(rows) <= 1 {
-		return nil, fmt.Errorf("sheet '%s' has no data", e.SheetName)
+		return nil, fmt.Errorf("sheet '%s' has no data", sheetName)
 	}
+	return sheetDataset(rows, sheetName, columnsMapper), nil
+}
 
-	columns := rows[0]
-	var data []map[string]interface{}
-
-	for _, row := range rows[1:] {
-		dataRow := make(map[string]interface{})
-		for colIndex, colCell := range row {
-			if colIndex >= len(columns) {
-				break
-			}
-			currentColumnName := strings.ToLower(strings.TrimSpace(columns[colIndex]))
-			mappedColumnName := currentColumnName
-			if e.ColumnsMapper != nil {
-				if mapped, ok := e.ColumnsMapper[currentColumnName]; ok {
-					mappedColumnName = mapped
-				}
-			}
-			dataRow[mappedColumnName] = colCell
+// sheetDataset keys the rows of a sheet by its normalized header row, fully blank rows are skipped.
+func sheetDataset(rows [][]string, sheetName string, columnsMapper map[string]string) *Dataset {
+	columns := make([]string, len(rows[0]))
+	for i, column := range rows[0] {
+		columns[i] = normalizeColumnName(column, columnsMapper)
+	}
+	var (
+		data       []map[string]interface{}
+		rowNumbers []int
+	)
+	for index, cells := range rows[1:] {
+		dataRow, blank := sheetRow(columns, cells)
+		if blank {
+			continue
 		}
 		data = append(data, dataRow)
+		rowNumbers = append(rowNumbers, index+2)
+	}
+
+	dataset := &Dataset{Rows: data, Columns: columns, Source: SourceExcel, Sheet: sheetName, FirstRow: 2}
+	if len(rowNumbers) > 0 && rowNumbers[len(rowNumbers)-1] != len(rowNumbers)+1 {
+		// blank rows were skipped, the rows no longer follow each other
+		dataset.RowNumbers = rowNumbers
 	}
+	return dataset
+}
 
-	return data, nil
+// sheetRow keys the cells of a sheet row by the header columns. Sheets leave out the trailing empty
+// cells of a row so every column gets a cell, blank reports a row without any non-blank cell.
+func sheetRow(columns []string, cells []string) (map[string]interface{}, bool) {
+	dataRow := make(map[string]interface{}, len(columns))
+	blank := true
+	for colIndex, column := range columns {
+		value := ""
+		if colIndex < len(cells) {
+			value = cells[colIndex]
+		}
+		if strings.TrimSpace(value) != "" {
+			blank = false
+		}
+		dataRow[column] = value
+	}
+	return dataRow, blank
+}
+
+// Load implementation for MemoryLoader
+func (m MemoryLoader) Load() ([]map[string]interface{}, error) {
+	return m.Rows, nil
+}
+
+// loadedDataset serves a dataset that is already loaded, keeping its source for error reports.
+type loadedDataset struct {
+	dataset *Dataset
+}
+
+func (l loadedDataset) Load() ([]map[string]interface{}, error) {
+	return l.dataset.Rows, nil
+}
+
+func (l loadedDataset) LoadDataset() (*Dataset, error) {
+	return l.dataset, nil
+}
+
+// Configs reads the workbook and returns one SeederConfig per sheet, ready for SeedAll.
+// A sheet is seeded into the table given by SheetTables, or into the table named after the sheet
+// where a "schema.table" sheet name sets the schema and SchemaName is used otherwise.
+// Empty and header-only sheets are skipped.
+func (w WorkbookLoader) Configs() ([]SeederConfig, error) {
+	f, err := excelize.OpenReader(&w.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Println("failed to close Excel file:", err)
+		}
+	}()
+
+	sheets := w.Sheets
+	if len(sheets) == 0 {
+		sheets = f.GetSheetList()
+	}
+	configs := make([]SeederConfig, 0, len(sheets))
+	for _, sheetName := range sheets {
+		rows, err := f.GetRows(sheetName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sheet '%s': %w", sheetName, err)
+		}
+		if len(rows) <= 1 {
+			continue
+		}
+		tableName := sheetName
+		if mapped, ok := w.SheetTables[sheetName]; ok {
+			tableName = mapped
+		}
+		schemaName := w.SchemaName
+		if parts := strings.SplitN(tableName, ".", 2); len(parts) == 2 {
+			schemaName, tableName = parts[0], parts[1]
+		}
+		if schemaName == "" {
+			return nil, fmt.Errorf("sheet '%s' has no schema, name it schema.table, map it in SheetTables or set SchemaName", sheetName)
+		}
+		configs = append(configs, SeederConfig{
+			Loader:     loadedDataset{dataset: sheetDataset(rows, sheetName, w.ColumnsMapper)},
+			SchemaName: strings.TrimSpace(schemaName),
+			TableName:  strings.TrimSpace(tableName),
+		})
+	}
+	return configs, nil
+}
+
+// Load implementation for CSVLoader
+func (c CSVLoader) Load() ([]map[string]interface{}, error) {
+	dataset, err := c.LoadDataset()
+	if err != nil {
+		return nil, err
+	}
+	return dataset.Rows, nil
+}
+
+// LoadDataset implementation for CSVLoader, records may span several lines (quoted line breaks)
+// or be separated by blank lines so every row is located at the line it starts on.
+func (c CSVLoader) LoadDataset() (*Dataset, error) {
+	rows, err := c.Stream()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var (
+		data       []map[string]interface{}
+		rowNumbers []int
+	)
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, row)
+		rowNumbers = append(rowNumbers, rows.(rowNumberIterator).rowNumber())
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("csv content has no data")
+	}
+	dataset := iteratorSource(rows)
+	dataset.Rows = data
+	dataset.RowNumbers = rowNumbers
+	return &dataset, nil
+}
+
+// normalizeColumnName lowercases and trims a header cell then applies the columns mapper.
+func normalizeColumnName(column string, columnsMapper map[string]string) string {
+	currentColumnName := strings.ToLower(strings.TrimSpace(column))
+	if columnsMapper != nil {
+		if mapped, ok := columnsMapper[currentColumnName]; ok {
+			return mapped
+		}
+	}
+	return currentColumnName
 }
 
 // SeederInterface defines methods for generating SQL from various sources
@@ -100,6 +356,23 @@ type SeederInterface interface {
 	// Seed is the unified method that accepts a SeederConfig
 	Seed(config SeederConfig) (string, error)
 
+	// SeedAll seeds several tables in one script, ordered so that every table comes after
+	// the tables its one-to-many and many-to-many columns look up.
+	SeedAll(configs []SeederConfig) (string, error)
+
+	// SeedWorkbook seeds every sheet of a workbook as a table using SeedAll.
+	SeedWorkbook(loader WorkbookLoader) (string, error)
+
+	// SeedParameterized returns the statements of the configs (ordered like SeedAll) with dialect
+	// placeholders and their ordered arguments instead of a literal script.
+	SeedParameterized(configs ...SeederConfig) ([]ParameterizedStatement, error)
+
+	// SeedTo streams the statements of a config to w in batches of rows instead of returning one string.
+	SeedTo(w io.Writer, config SeederConfig) error
+
+	// Execute seeds the configs (ordered like SeedAll) directly into a database with bound parameters.
+	Execute(ctx context.Context, db SQLExecutor, configs ...SeederConfig) (*ExecutionResult, error)
+
 	// Legacy methods - kept for backward compatibility
 	// SeedFromJSON(jsonContent bytes.Buffer, schemaName string, tableName string) (string, error)
 	// SeedFromExcel(excelContent bytes.Buffer, schemaName string, tableName string, sheetName string, columnsMapper map[string]string) (string, error)
@@ -115,8 +388,17 @@ type Seeder struct {
 	ArrayDelimiter string
 	Embed          func(ctx context.Context, text string, model ...string) ([][]float32, error)
 	EmbedBulk      func(ctx context.Context, text []string, model ...string) ([][][]float32, error)
+	EmbedBatchSize int
+	EmbedModel     string
 	HashFunc       func(string) string
 	Adapter        AdapterInterface
+	Dialect        Dialect
+
+	MaxRowsPerStatement int
+	CollectErrors       bool
+	LookupResolver      LookupResolver
+
+	resolveSeeded bool
 }
 
 type SeederConfigInit struct {
@@ -124,10 +406,51 @@ type SeederConfigInit struct {
 	HashFunc               func(string) string
 	Embed                  func(ctx context.Context, text string, model ...string) ([][]float32, error)
 	EmbedBulk              func(ctx context.Context, text []string, model ...string) ([][][]float32, error)
+	EmbedBatchSize         int    // optional - texts per EmbedBulk call, defaults to 100
+	EmbedModel             string // optional - model passed to Embed / EmbedBulk
 	ColumnsMapper          map[string]string
 	ManyToManyRowDelimiter string
 	ArrayDelimiter         string
 	ManyToManyDelimiter    string
+	Dialect                Dialect // optional - defaults to PostgresDialect
+	// TemplatePath, TemplateFS and TemplateString replace the embedded insert template,
+	// TemplateFuncs adds functions to (or overrides functions of) the template.
+	TemplatePath   string
+	TemplateFS     fs.FS
+	TemplateString string
+	TemplateFuncs  template.FuncMap
+	// RawSQLPrefix marks a cell value as a raw SQL expression (e.g. RawSQLMarker), it is unset by
+	// default so no cell is read as SQL. A column suffixed with !raw treats all its values as raw SQL.
+	// RawSQLAllowlist restricts raw SQL to calls of the listed functions and DisableRawSQL rejects
+	// raw SQL altogether.
+	RawSQLPrefix    string
+	RawSQLAllowlist []string
+	DisableRawSQL   bool
+	// MaxRowsPerStatement splits the rows of every table (and many-to-many join table) into
+	// several statements of at most this many rows, 0 keeps one statement per table.
+	MaxRowsPerStatement int
+	// CollectErrors reports every invalid header and cell of a table as CellErrors
+	// instead of failing on the first one.
+	CollectErrors bool
+	// ColumnOrder sets the order of the columns in the statements, defaults to the source order
+	// (header order of sheets and CSV, key order of JSON objects).
+	ColumnOrder ColumnOrder
+	// MissingValue sets the value of the columns a row doesn't have (JSON records with fewer keys),
+	// defaults to NULL.
+	MissingValue MissingValue
+	// StrictLookups fails on one-to-many and many-to-many values that don't match any row instead of
+	// inserting NULL: generated scripts check them with a guard before every statement (PostgreSQL and
+	// SQL Server) and Execute checks them against the database before running each statement.
+	StrictLookups bool
+	// LookupResolver resolves the lookup values of every table once before the SQL is generated,
+	// so that statements hold literal ids instead of subqueries, e.g. NewDatabaseResolver(db, dialect).
+	// Values it can't resolve keep their subquery.
+	LookupResolver LookupResolver
+	// ResolveSeededLookups resolves lookups to the ids of rows seeded earlier in the same Seed, SeedAll,
+	// SeedTo, SeedParameterized or Execute call with their primary key column (e.g. category_id for
+	// categories), no database is needed. The call keeps the id and looked up search values of every
+	// such row until it returns, SeedTo keeps them across its batches.
+	ResolveSeededLookups bool
 }
 
 func NewSeeder(config SeederConfigInit) SeederInterface {
@@ -146,16 +469,50 @@ func NewSeeder(config SeederConfigInit) SeederInterface {
 	if config.ManyToManyRowDelimiter != "" {
 		delimiter = config.ManyToManyRowDelimiter
 	}
-	adapter := NewAdapter(oneToManyDelimiter, manyToManyDelimiter)
-	generator := NewGenerator(adapter, config.ColumnsMapper, delimiter, config.ArrayDelimiter, oneToManyDelimiter, manyToManyDelimiter, config.HashFunc)
+	if config.Dialect == nil {
+		config.Dialect = PostgresDialect{}
+	}
+	if config.EmbedBatchSize <= 0 {
+		config.EmbedBatchSize = defaultEmbedBatchSize
+	}
+	adapter := NewAdapter(oneToManyDelimiter, manyToManyDelimiter, WithDialect(config.Dialect))
+	generator := NewGeneratorWithConfig(adapter, GeneratorConfig{
+		ColumnsMapper:       config.ColumnsMapper,
+		Delimiter:           delimiter,
+		ArrayDelimiter:      config.ArrayDelimiter,
+		OneToManyDelimiter:  oneToManyDelimiter,
+		ManyToManyDelimiter: manyToManyDelimiter,
+		HashFunc:            config.HashFunc,
+		Dialect:             config.Dialect,
+		TemplatePath:        config.TemplatePath,
+		TemplateFS:          config.TemplateFS,
+		TemplateString:      config.TemplateString,
+		TemplateFuncs:       config.TemplateFuncs,
+		RawSQLPrefix:        config.RawSQLPrefix,
+		RawSQLAllowlist:     config.RawSQLAllowlist,
+		DisableRawSQL:       config.DisableRawSQL,
+		MaxRowsPerStatement: config.MaxRowsPerStatement,
+		CollectErrors:       config.CollectErrors,
+		ColumnOrder:         config.ColumnOrder,
+		MissingValue:        config.MissingValue,
+		StrictLookups:       config.StrictLookups,
+	})
 	return &Seeder{
 		Adapter:        adapter,
 		Embed:          config.Embed,
 		EmbedBulk:      config.EmbedBulk,
+		EmbedBatchSize: config.EmbedBatchSize,
+		EmbedModel:     config.EmbedModel,
 		HashFunc:       config.HashFunc,
 		Delimiter:      delimiter,
 		ArrayDelimiter: config.ArrayDelimiter,
 		Generator:      generator,
+		Dialect:        config.Dialect,
+
+		MaxRowsPerStatement: config.MaxRowsPerStatement,
+		CollectErrors:       config.CollectErrors,
+		LookupResolver:      config.LookupResolver,
+		resolveSeeded:       config.ResolveSeededLookups,
 	}
 }
 
@@ -170,27 +527,160 @@ func (s *Seeder) GetAdapter() AdapterInterface {
 // Seed is the unified method
 func (s *Seeder) Seed(config SeederConfig) (string, error) {
 	// Load data using the provided loader
-	data, err := config.Loader.Load()
+	dataset, err := loadDataset(config.Loader)
+	if err != nil {
+		return "", err
+	}
+	return s.seedData(config, dataset, s.newSeededRows([]SeederConfig{config}, []*Dataset{dataset}))
+}
+
+// SeedWorkbook seeds every sheet of a workbook as a table using SeedAll
+func (s *Seeder) SeedWorkbook(loader WorkbookLoader) (string, error) {
+	configs, err := loader.Configs()
 	if err != nil {
 		return "", err
 	}
+	return s.SeedAll(configs)
+}
 
+// seedData generates the SQL of a config from its already loaded data.
+func (s *Seeder) seedData(config SeederConfig, dataset *Dataset, seeded *seededRows) (string, error) {
 	// If FunctionName is provided, use function-based import
 	if config.FunctionName != "" {
-		return s.generateFunctionCall(data, config.FunctionName)
+		return s.generateFunctionCall(dataset.Rows, config.FunctionName)
 	}
 
-	// Otherwise, use table-based import
+	sqlData, err := s.buildSQLData(config, dataset, seeded)
+	if err != nil {
+		return "", err
+	}
+	return s.Generator.Generate(*sqlData)
+}
+
+// buildSQLData validates a table-based config and generates its statements from the loaded data,
+// cell errors of the generator are located in the source of the dataset. Lookups are resolved with the
+// rows seeded earlier in the call when seeded is not nil.
+func (s *Seeder) buildSQLData(config SeederConfig, dataset *Dataset, seeded *seededRows) (*SQLData, error) {
 	if config.SchemaName == "" || config.TableName == "" {
-		return "", fmt.Errorf("SchemaName and TableName are required when FunctionName is not provided")
+		return nil, fmt.Errorf("SchemaName and TableName are required when FunctionName is not provided")
+	}
+
+	if err := s.validateConflict(config.Conflict); err != nil {
+		return nil, err
 	}
 
-	sqlData, err := s.Generator.GenerateTableData(data, config.SchemaName, config.TableName)
+	ctx := config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rows, err := s.embedColumns(ctx, dataset.Rows)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return s.Generator.Generate(*sqlData)
+	sqlData, err := s.Generator.GenerateOrderedTableData(s.applyBlanks(config, rows), dataset.Columns, config.SchemaName, config.TableName)
+	if err != nil {
+		return nil, dataset.locate(err)
+	}
+	dataset.locateLookups(sqlData)
+	// the conflict config only applies to the seeded table, join rows are always skipped on conflict
+	// and auto-created parents (which have no schema) are only inserted when they don't exist
+	for i, stmt := range sqlData.Statements {
+		if stmt.Schema == config.SchemaName && stmt.Table == config.TableName {
+			sqlData.Statements[i].Conflict = config.Conflict
+			break
+		}
+	}
+	if err := s.resolveLookups(ctx, sqlData, seeded); err != nil {
+		return nil, err
+	}
+	return sqlData, nil
+}
+
+// resolveLookups resolves the lookups of the statements with the rows seeded earlier and then with
+// the LookupResolver, the seeded rows are recorded first so that a table can reference itself.
+func (s *Seeder) resolveLookups(ctx context.Context, sqlData *SQLData, seeded *seededRows) error {
+	var resolvers lookupResolvers
+	if seeded != nil {
+		seeded.record(sqlData.Statements)
+		resolvers = append(resolvers, seeded)
+	}
+	if s.LookupResolver != nil {
+		resolvers = append(resolvers, s.LookupResolver)
+	}
+	if len(resolvers) == 0 {
+		return nil
+	}
+	return s.Generator.ResolveLookups(ctx, sqlData, resolvers)
+}
+
+// newSeededRows returns the store of the rows seeded by one call with the lookups of the loaded configs
+// registered, nil when ResolveSeededLookups is not set.
+func (s *Seeder) newSeededRows(configs []SeederConfig, datasets []*Dataset) *seededRows {
+	if !s.resolveSeeded {
+		return nil
+	}
+	seeded := &seededRows{generator: s.Generator, adapter: s.Adapter}
+	for i, config := range configs {
+		if config.FunctionName == "" {
+			seeded.needColumns(config.SchemaName, config.TableName, datasets[i].Rows)
+		}
+	}
+	return seeded
+}
+
+// applyBlanks returns copies of the rows with their blank cells replaced following the blank policy of the config.
+func (s *Seeder) applyBlanks(config SeederConfig, data []map[string]interface{}) []map[string]interface{} {
+	if config.Blanks == BlankNull && len(config.ColumnBlanks) == 0 {
+		return data
+	}
+	result := copyRows(data)
+	for _, row := range result {
+		for column, value := range row {
+			if str, ok := value.(string); ok && strings.TrimSpace(str) == "" && !s.Adapter.IsManyToMany(column) {
+				row[column] = s.blankValue(config, column)
+			}
+		}
+	}
+	return result
+}
+
+// blankValue returns the value of a blank cell of column.
+func (s *Seeder) blankValue(config SeederConfig, column string) interface{} {
+	policy, ok := config.ColumnBlanks[column]
+	if !ok {
+		policy, ok = config.ColumnBlanks[s.Generator.GetColumnName(column)]
+	}
+	if !ok {
+		policy = config.Blanks
+	}
+	switch policy {
+	case BlankDefault:
+		return DefaultValue{}
+	case BlankEmpty:
+		return "EMPTY"
+	case BlankOmit:
+		return OmittedValue{}
+	default:
+		return nil
+	}
+}
+
+// validateConflict checks that a conflict config can be expressed in the seeder dialect.
+func (s *Seeder) validateConflict(conflict ConflictConfig) error {
+	if conflict.Action != ConflictDoUpdate {
+		return nil
+	}
+	if conflict.Constraint != "" {
+		if _, ok := s.Dialect.(PostgresDialect); !ok {
+			return fmt.Errorf("conflict constraint is only supported by the postgres dialect, use Conflict.Target for %s", s.Dialect.Name())
+		}
+		return nil
+	}
+	if len(conflict.Target) == 0 {
+		return fmt.Errorf("Conflict.Target or Conflict.Constraint is required when Conflict.Action is ConflictDoUpdate")
+	}
+	return nil
 }
 
 // generateFunctionCall generates a SELECT statement calling a SQL function with JSON data
@@ -201,13 +691,8 @@ func (s *Seeder) generateFunctionCall(data []map[string]interface{}, functionNam
 		return "", fmt.Errorf("failed to marshal data to JSON: %w", err)
 	}
 
-	// Escape single quotes in JSON for SQL
-	jsonStr := string(jsonBytes)
-	jsonStr = strings.ReplaceAll(jsonStr, "'", "''")
-
-	// Generate SELECT statement
-	sql := fmt.Sprintf("SELECT %s('%s'::JSONB);", functionName, jsonStr)
-	return sql, nil
+	// Generate the dialect specific function call with the JSON literal
+	return s.Dialect.FunctionCall(functionName, s.Dialect.StringLiteral(string(jsonBytes))), nil
 }
 
 //
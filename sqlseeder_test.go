@@ -0,0 +1,346 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSeeder_SeedJSONTypedValues(t *testing.T) {
+	content := `[{"product_name": "Laptop", "price": 12.5, "active": true, "discount": null, "specs": {"ram": 16}}]`
+
+	result, err := seeder.Seed(SeederConfig{
+		Loader:     JsonLoader{Content: *bytes.NewBufferString(content)},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	require.NoError(t, err)
+	require.Contains(t, result, "INSERT INTO public.products")
+	for _, literal := range []string{"'Laptop'", "12.5", "TRUE", "NULL", `'{"ram":16}'::jsonb`} {
+		require.Contains(t, result, literal)
+	}
+}
+
+func TestSeeder_SeedConflictValidation(t *testing.T) {
+	config := SeederConfig{
+		Loader:     JsonLoader{Content: *bytes.NewBufferString(`[{"category_name": "Books"}]`)},
+		SchemaName: "public",
+		TableName:  "categories",
+		Conflict:   ConflictConfig{Action: ConflictDoUpdate},
+	}
+	_, err := seeder.Seed(config)
+	require.Error(t, err)
+
+	config.Loader = JsonLoader{Content: *bytes.NewBufferString(`[{"category_name": "Books"}]`)}
+	config.Conflict.Constraint = "categories_name_key"
+	_, err = NewSeeder(SeederConfigInit{Dialect: MySQLDialect{}}).Seed(config)
+	require.Error(t, err)
+
+	config.Loader = JsonLoader{Content: *bytes.NewBufferString(`[{"category_name": "Books", "sort": 1}]`)}
+	config.Conflict = ConflictConfig{Action: ConflictDoUpdate, Target: []string{"category_name"}}
+	result, err := seeder.Seed(config)
+	require.NoError(t, err)
+	require.Contains(t, result, "ON CONFLICT (category_name) DO UPDATE SET sort = EXCLUDED.sort;")
+}
+
+func newTestWorkbook(t *testing.T, sheets map[string][][]interface{}, order []string) bytes.Buffer {
+	f := excelize.NewFile()
+	for i, sheetName := range order {
+		if i == 0 {
+			require.NoError(t, f.SetSheetName("Sheet1", sheetName))
+		} else {
+			_, err := f.NewSheet(sheetName)
+			require.NoError(t, err)
+		}
+		for rowIndex, row := range sheets[sheetName] {
+			cell, err := excelize.CoordinatesToCellName(1, rowIndex+1)
+			require.NoError(t, err)
+			require.NoError(t, f.SetSheetRow(sheetName, cell, &row))
+		}
+	}
+	buffer, err := f.WriteToBuffer()
+	require.NoError(t, err)
+	return *buffer
+}
+
+func TestSeeder_SeedMaxRowsPerStatement(t *testing.T) {
+	content := `[
+		{"product_name": "p1", "tag_id***product_tags***tags***tag_name***product_name": "a|b|c"},
+		{"product_name": "p2", "tag_id***product_tags***tags***tag_name***product_name": "d"},
+		{"product_name": "p3", "tag_id***product_tags***tags***tag_name***product_name": "e"},
+		{"product_name": "p4", "tag_id***product_tags***tags***tag_name***product_name": "f"},
+		{"product_name": "p5", "tag_id***product_tags***tags***tag_name***product_name": "g"}
+	]`
+	chunkedSeeder := NewSeeder(SeederConfigInit{MaxRowsPerStatement: 2})
+	result, err := chunkedSeeder.Seed(SeederConfig{
+		Loader:     JsonLoader{Content: *bytes.NewBufferString(content)},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, strings.Count(result, "INSERT INTO public.products"))
+	require.Equal(t, 4, strings.Count(result, "INSERT INTO product_tags"))
+	require.Equal(t, 7, strings.Count(result, "FROM tags WHERE"))
+
+	statements, err := chunkedSeeder.SeedParameterized(SeederConfig{
+		Loader:     JsonLoader{Content: *bytes.NewBufferString(content)},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	require.NoError(t, err)
+	require.Len(t, statements, 7)
+	for _, statement := range statements {
+		require.LessOrEqual(t, len(statement.Args), 4)
+	}
+}
+
+func TestWorkbookLoader_Configs(t *testing.T) {
+	content := newTestWorkbook(t, map[string][][]interface{}{
+		"products":         {{"Product_Name", "category_id**categories**category_name"}, {"Laptop", "Electronics"}},
+		"store.categories": {{"category_name"}, {"Electronics"}},
+		"notes":            {{"note"}, {"ignored"}},
+		"Tags Sheet":       {{"tag_name"}, {"new"}},
+	}, []string{"products", "store.categories", "notes", "Tags Sheet"})
+
+	loader := WorkbookLoader{
+		Content:     content,
+		Sheets:      []string{"products", "store.categories", "Tags Sheet"},
+		SheetTables: map[string]string{"Tags Sheet": "tags"},
+		SchemaName:  "public",
+	}
+	configs, err := loader.Configs()
+	require.NoError(t, err)
+	require.Len(t, configs, 3)
+	require.Equal(t, "public", configs[0].SchemaName)
+	require.Equal(t, "products", configs[0].TableName)
+	require.Equal(t, "store", configs[1].SchemaName)
+	require.Equal(t, "categories", configs[1].TableName)
+	require.Equal(t, "tags", configs[2].TableName)
+	rows, err := configs[0].Loader.Load()
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{"product_name": "Laptop", "category_id**categories**category_name": "Electronics"}}, rows)
+
+	loader.Content = content
+	result, err := seeder.SeedWorkbook(loader)
+	require.NoError(t, err)
+	require.Less(t, strings.Index(result, "INSERT INTO store.categories"), strings.Index(result, "INSERT INTO public.products"))
+
+	_, err = WorkbookLoader{Content: content}.Configs()
+	require.Error(t, err)
+}
+
+func TestWorkbookLoader_ConfigsSkipEmptySheets(t *testing.T) {
+	content := newTestWorkbook(t, map[string][][]interface{}{
+		"products":   {{"product_name"}, {"Laptop"}},
+		"categories": {{"category_name"}},
+	}, []string{"products", "categories", "notes"})
+
+	configs, err := WorkbookLoader{Content: content, SchemaName: "public"}.Configs()
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	require.Equal(t, "products", configs[0].TableName)
+}
+
+func TestSeeder_SeedColumnOrder(t *testing.T) {
+	json := `[{"product_name": "Laptop", "price": 10, "category_id**categories**category_name": "Electronics"}, {"sku": "x1", "price": 5, "product_name": "Mouse"}]`
+	csv := "product_name,price,category_id**categories**category_name\nLaptop,10,Electronics\n"
+	content := newTestWorkbook(t, map[string][][]interface{}{
+		"products": {{"product_name", "price", "category_id**categories**category_name"}, {"Laptop", 10, "Electronics"}},
+	}, []string{"products"})
+	loaders := map[string]DataLoader{
+		"json":  JsonLoader{Content: *bytes.NewBufferString(json)},
+		"csv":   CSVLoader{Content: *bytes.NewBufferString(csv)},
+		"excel": ExcelLoader{Content: content, SheetName: "products"},
+	}
+	for name, loader := range loaders {
+		t.Run(name, func(t *testing.T) {
+			result, err := seeder.Seed(SeederConfig{Loader: loader, SchemaName: "public", TableName: "products"})
+			require.NoError(t, err)
+			require.Contains(t, result, "INSERT INTO public.products (product_name, price, category_id")
+		})
+	}
+
+	dataset, err := JsonLoader{Content: *bytes.NewBufferString(json)}.LoadDataset()
+	require.NoError(t, err)
+	require.Equal(t, []string{"product_name", "price", "category_id**categories**category_name", "sku"}, dataset.Columns)
+
+	alphabetical := NewSeeder(SeederConfigInit{ColumnOrder: ColumnOrderAlphabetical})
+	result, err := alphabetical.Seed(SeederConfig{Loader: JsonLoader{Content: *bytes.NewBufferString(json)}, SchemaName: "public", TableName: "products"})
+	require.NoError(t, err)
+	require.Contains(t, result, "INSERT INTO public.products (category_id, price, product_name, sku)")
+}
+
+func TestSeeder_SeedMissingValues(t *testing.T) {
+	content := `[{"product_name": "Laptop", "price": 10}, {"product_name": "Mouse", "sku": "m1"}, {"product_name": "Pad", "sku": "p1"}]`
+	config := func() SeederConfig {
+		return SeederConfig{Loader: JsonLoader{Content: *bytes.NewBufferString(content)}, SchemaName: "public", TableName: "products"}
+	}
+
+	result, err := seeder.Seed(config())
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO public.products (product_name, price, sku) VALUES ( 'Laptop', 10, NULL ), ( 'Mouse', NULL, 'm1' ), ( 'Pad', NULL, 'p1' ) ON CONFLICT DO NOTHING;", strings.Join(strings.Fields(result), " "))
+
+	defaults := NewSeeder(SeederConfigInit{MissingValue: MissingValueDefault})
+	result, err = defaults.Seed(config())
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO public.products (product_name, price, sku) VALUES ( 'Laptop', 10, DEFAULT ), ( 'Mouse', DEFAULT, 'm1' ), ( 'Pad', DEFAULT, 'p1' ) ON CONFLICT DO NOTHING;", strings.Join(strings.Fields(result), " "))
+}
+
+func TestSeeder_SeedBlankPolicy(t *testing.T) {
+	content := "name,created_at,is_active,tags[],category_id**categories**category_name,tag_id***product_tags***tags***tag_name***name\n" +
+		"Laptop,,,,,\n" +
+		"Mouse,EMPTY,true,EMPTY,EMPTY,EMPTY\n"
+	seed := func(config SeederConfig) string {
+		config.Loader = CSVLoader{Content: *bytes.NewBufferString(content)}
+		config.SchemaName = "public"
+		config.TableName = "products"
+		result, err := seeder.Seed(config)
+		require.NoError(t, err)
+		return strings.Join(strings.Fields(result), " ")
+	}
+
+	require.Equal(t, "INSERT INTO public.products (name, created_at, is_active, tags, category_id) VALUES "+
+		"( 'Laptop', NULL, NULL, NULL, NULL ), ( 'Mouse', '', 'true', '{}', NULL ) ON CONFLICT DO NOTHING;",
+		seed(SeederConfig{}))
+
+	require.Equal(t, "INSERT INTO public.products (name, created_at, is_active, tags, category_id) VALUES "+
+		"( 'Laptop', DEFAULT, DEFAULT, '{}', NULL ), ( 'Mouse', '', 'true', '{}', NULL ) ON CONFLICT DO NOTHING;",
+		seed(SeederConfig{Blanks: BlankDefault, ColumnBlanks: map[string]BlankPolicy{"tags": BlankEmpty, "category_id": BlankNull}}))
+
+	require.Equal(t, "INSERT INTO public.products (name, is_active, tags, category_id) VALUES "+
+		"( 'Laptop', NULL, NULL, NULL ) ON CONFLICT DO NOTHING; "+
+		"INSERT INTO public.products (name, created_at, is_active, tags, category_id) VALUES "+
+		"( 'Mouse', '', 'true', '{}', NULL ) ON CONFLICT DO NOTHING;",
+		seed(SeederConfig{ColumnBlanks: map[string]BlankPolicy{"created_at": BlankOmit}}))
+
+	require.Equal(t, "INSERT INTO public.products (name, created_at, is_active, tags, category_id) VALUES "+
+		"( 'Laptop', '', '', '{}', NULL ), ( 'Mouse', '', 'true', '{}', NULL ) ON CONFLICT DO NOTHING;",
+		seed(SeederConfig{Blanks: BlankEmpty}))
+}
+
+func TestSeeder_SeedExcelBlankCells(t *testing.T) {
+	content := newTestWorkbook(t, map[string][][]interface{}{
+		"products": {
+			{"name", "notes"},
+			{"Laptop", ""},
+			{},
+			{"", " "},
+			{"Mouse", "wireless"},
+		},
+	}, []string{"products"})
+	loader := ExcelLoader{Content: content, SheetName: "products"}
+	expected := []map[string]interface{}{{"name": "Laptop", "notes": ""}, {"name": "Mouse", "notes": "wireless"}}
+
+	dataset, err := ExcelLoader{Content: *bytes.NewBuffer(content.Bytes()), SheetName: "products"}.LoadDataset()
+	require.NoError(t, err)
+	require.Equal(t, expected, dataset.Rows)
+	require.Equal(t, []int{2, 5}, dataset.RowNumbers)
+	rows, err := ExcelLoader{Content: *bytes.NewBuffer(content.Bytes()), SheetName: "products"}.Stream()
+	require.NoError(t, err)
+	require.Equal(t, expected, collectRows(t, rows))
+
+	// the trailing blank cell follows the blank policy instead of MissingValue
+	for _, seed := range []func(SeederConfig) (string, error){
+		seeder.Seed,
+		func(config SeederConfig) (string, error) {
+			var script bytes.Buffer
+			err := seeder.SeedTo(&script, config)
+			return script.String(), err
+		},
+	} {
+		result, err := seed(SeederConfig{
+			Loader:     ExcelLoader{Content: *bytes.NewBuffer(loader.Content.Bytes()), SheetName: "products"},
+			SchemaName: "public",
+			TableName:  "products",
+			Blanks:     BlankDefault,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "INSERT INTO public.products (name, notes) VALUES ( 'Laptop', DEFAULT ), ( 'Mouse', 'wireless' ) ON CONFLICT DO NOTHING;", strings.Join(strings.Fields(result), " "))
+	}
+}
+
+func TestSeeder_SeedManyToManyAttributes(t *testing.T) {
+	result, err := seeder.Seed(jsonConfig("products", `[
+		{"product_name": "Laptop", "tag_id***product_tags***tags***tag_name***product_name***sort_order:is_primary": "tag1:1:true|tag2:2.5:FALSE|tag3:007:yes"}
+	]`))
+	require.NoError(t, err)
+	// numbers and booleans are typed like JSON values, codes with leading zeros stay text
+	result = strings.Join(strings.Fields(result), " ")
+	require.Contains(t, result, "(SELECT tag_id FROM tags WHERE tag_name = 'tag1'), 1, TRUE )")
+	require.Contains(t, result, "(SELECT tag_id FROM tags WHERE tag_name = 'tag2'), 2.5, FALSE )")
+	require.Contains(t, result, "(SELECT tag_id FROM tags WHERE tag_name = 'tag3'), '007', 'yes' )")
+
+	_, err = NewSeeder(SeederConfigInit{RawSQLPrefix: RawSQLMarker, DisableRawSQL: true}).Seed(jsonConfig("products", `[
+		{"product_name": "Laptop", "tag_id***product_tags***tags***tag_name***product_name***sort_order": "tag1:sql:pg_sleep(1)"}
+	]`))
+	require.EqualError(t, err, "json row 1, column 'tag_id***product_tags***tags***tag_name***product_name***sort_order': attribute 'sort_order': raw SQL is disabled, found the raw expression 'pg_sleep(1)'")
+}
+
+func TestSeeder_SeedCompositeLookups(t *testing.T) {
+	result, err := seeder.Seed(jsonConfig("stores", `[
+		{"store_name": "Downtown", "city_id**cities**country_code+city_name": "EG+Cairo", "area_id***store_areas***areas***city_code+area_name***store_name": "CAI+Zamalek|CAI+Maadi"}
+	]`))
+	require.NoError(t, err)
+	result = strings.Join(strings.Fields(result), " ")
+	require.Contains(t, result, "( 'Downtown', (SELECT city_id FROM cities WHERE country_code = 'EG' AND city_name = 'Cairo') )")
+	require.Contains(t, result, "(SELECT area_id FROM areas WHERE city_code = 'CAI' AND area_name = 'Zamalek') )")
+	require.Contains(t, result, "(SELECT area_id FROM areas WHERE city_code = 'CAI' AND area_name = 'Maadi') )")
+}
+
+func TestSeeder_SeedAutoCreate(t *testing.T) {
+	result, err := seeder.Seed(SeederConfig{
+		Loader: jsonConfig("products", `[
+			{"product_name": "Laptop", "category_id**categories+**category_name": "Electronics", "tag_id***product_tags***tags+***tag_name***product_name": "new|sale"},
+			{"product_name": "Phone", "category_id**categories+**category_name": "Electronics", "tag_id***product_tags***tags+***tag_name***product_name": "new"}
+		]`).Loader,
+		SchemaName: "public",
+		TableName:  "products",
+		Conflict:   ConflictConfig{Action: ConflictDoUpdate, Target: []string{"product_name"}},
+	})
+	require.NoError(t, err)
+	statements := strings.Split(strings.Join(strings.Fields(result), " "), "; ")
+	require.Equal(t, []string{
+		"INSERT INTO categories (category_name) SELECT 'Electronics' WHERE NOT EXISTS (SELECT 1 FROM categories WHERE category_name = 'Electronics')",
+		"INSERT INTO tags (tag_name) SELECT 'new' WHERE NOT EXISTS (SELECT 1 FROM tags WHERE tag_name = 'new') " +
+			"UNION ALL SELECT 'sale' WHERE NOT EXISTS (SELECT 1 FROM tags WHERE tag_name = 'sale')",
+		"INSERT INTO public.products (product_name, category_id) VALUES " +
+			"( 'Laptop', (SELECT category_id FROM categories WHERE category_name = 'Electronics') ), " +
+			"( 'Phone', (SELECT category_id FROM categories WHERE category_name = 'Electronics') ) " +
+			"ON CONFLICT (product_name) DO UPDATE SET category_id = EXCLUDED.category_id",
+	}, statements[:3])
+	require.Contains(t, statements[3], "INSERT INTO product_tags (product_id, tag_id) VALUES")
+}
+
+func TestSeeder_SeedChildRows(t *testing.T) {
+	result, err := seeder.Seed(jsonConfig("orders", `[
+		{"order_number": "A1", "order_lines>order_number": [{"product_id**products**sku": "X", "qty": 2}, {"product_id**products**sku": "Y", "qty": 1}]},
+		{"order_number": "A2", "order_lines>order_number": null},
+		{"order_number": "A3", "order_lines>order_number": [{"product_id**products**sku": "X", "qty": 4}]}
+	]`))
+	require.NoError(t, err)
+	statements := strings.Split(strings.Join(strings.Fields(result), " "), "; ")
+	require.Equal(t, []string{
+		"INSERT INTO public.orders (order_number) VALUES ( 'A1' ), ( 'A2' ), ( 'A3' ) ON CONFLICT DO NOTHING",
+		"INSERT INTO public.order_lines (order_id, product_id, qty) VALUES " +
+			"( (SELECT order_id FROM public.orders WHERE order_number = 'A1'), (SELECT product_id FROM products WHERE sku = 'X'), 2 ), " +
+			"( (SELECT order_id FROM public.orders WHERE order_number = 'A1'), (SELECT product_id FROM products WHERE sku = 'Y'), 1 ), " +
+			"( (SELECT order_id FROM public.orders WHERE order_number = 'A3'), (SELECT product_id FROM products WHERE sku = 'X'), 4 ) " +
+			"ON CONFLICT DO NOTHING;",
+	}, statements)
+
+	_, err = seeder.Seed(jsonConfig("orders", `[
+		{"order_number": "A1", "order_lines>order_number": [{"qty": 2}]},
+		{"order_number": "A2", "order_lines>order_number": [{"qty": 1}, {"qty": "sql:now()", "city_id**cities**country_code+city_name": "Cairo"}]}
+	]`))
+	var cellErr *CellError
+	require.ErrorAs(t, err, &cellErr)
+	require.Equal(t, 2, cellErr.Row)
+	require.Equal(t, "order_lines>order_number", cellErr.Column)
+	require.Contains(t, cellErr.Error(), "order_lines row 2, column 'city_id**cities**country_code+city_name': the lookup value 'Cairo' has 1 of the 2 values")
+
+	_, err = seeder.Seed(jsonConfig("orders", `[{"order_number": "A1", "order_lines>order_number": "X"}]`))
+	require.ErrorContains(t, err, "expected an array of order_lines rows, found 'X'")
+}
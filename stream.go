@@ -0,0 +1,534 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// defaultStreamBatchSize is the number of rows SeedTo generates at once when MaxRowsPerStatement is not set.
+const defaultStreamBatchSize = 1000
+
+// RowIterator yields loaded rows one at a time, Next returns io.EOF after the last row.
+type RowIterator interface {
+	Next() (map[string]interface{}, error)
+	Close() error
+}
+
+// StreamLoader is a DataLoader that can also read its rows one at a time
+// instead of loading the whole dataset in memory.
+type StreamLoader interface {
+	DataLoader
+	Stream() (RowIterator, error)
+}
+
+// streamRows returns a row iterator over any loader, loaders that don't stream are loaded at once.
+func streamRows(loader DataLoader) (RowIterator, error) {
+	if streamLoader, ok := loader.(StreamLoader); ok {
+		return streamLoader.Stream()
+	}
+	dataset, err := loadDataset(loader)
+	if err != nil {
+		return nil, err
+	}
+	return &sliceIterator{rows: dataset.Rows, dataset: *dataset}, nil
+}
+
+// sourcedIterator is implemented by the built-in iterators to describe the source of their rows.
+type sourcedIterator interface {
+	source() Dataset
+}
+
+// iteratorSource returns the source of the rows of an iterator, without rows.
+func iteratorSource(rows RowIterator) Dataset {
+	if sourced, ok := rows.(sourcedIterator); ok {
+		return sourced.source()
+	}
+	return Dataset{FirstRow: 1}
+}
+
+// rowNumberIterator is implemented by the iterators whose rows don't follow each other in the source,
+// rowNumber returns the 1-based source row of the row Next returned last.
+type rowNumberIterator interface {
+	rowNumber() int
+}
+
+// sliceIterator iterates over rows that are already loaded.
+type sliceIterator struct {
+	rows    []map[string]interface{}
+	pos     int
+	dataset Dataset
+}
+
+func (it *sliceIterator) source() Dataset {
+	return Dataset{
+		Columns:    it.dataset.Columns,
+		Source:     it.dataset.Source,
+		Sheet:      it.dataset.Sheet,
+		FirstRow:   it.dataset.FirstRow,
+		RowNumbers: it.dataset.RowNumbers,
+	}
+}
+
+func (it *sliceIterator) Next() (map[string]interface{}, error) {
+	if it.pos >= len(it.rows) {
+		return nil, io.EOF
+	}
+	row := it.rows[it.pos]
+	it.pos++
+	return row, nil
+}
+
+func (it *sliceIterator) Close() error {
+	return nil
+}
+
+// Stream implementation for JsonLoader, the objects of the top level array are decoded one by one
+// as they are read from Reader (or Content).
+func (j JsonLoader) Stream() (RowIterator, error) {
+	var reader io.Reader = bytes.NewReader(j.Content.Bytes())
+	if j.Reader != nil {
+		reader = j.Reader
+	}
+	decoder := json.NewDecoder(reader)
+	// keep numbers as json.Number so large ids and decimals are written exactly as provided
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("failed to parse JSON: expected an array of objects")
+	}
+	return &jsonIterator{decoder: decoder, seen: make(map[string]bool)}, nil
+}
+
+type jsonIterator struct {
+	decoder *json.Decoder
+	columns []string
+	seen    map[string]bool
+	done    bool
+}
+
+func (it *jsonIterator) Next() (map[string]interface{}, error) {
+	if it.done {
+		return nil, io.EOF
+	}
+	if !it.decoder.More() {
+		it.done = true
+		// consume the closing bracket of the array
+		if _, err := it.decoder.Token(); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return nil, io.EOF
+	}
+	row, keys, err := decodeObject(it.decoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	for _, key := range keys {
+		if !it.seen[key] {
+			it.seen[key] = true
+			it.columns = append(it.columns, key)
+		}
+	}
+	return row, nil
+}
+
+// decodeObject decodes the next JSON object of decoder and returns its keys in document order.
+func decodeObject(decoder *json.Decoder) (map[string]interface{}, []string, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("expected an object, found %v", token)
+	}
+	row := make(map[string]interface{})
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		// object keys are always returned as strings
+		key := token.(string)
+		var value interface{}
+		if strings.Contains(key, ChildRowsDelimiter) {
+			value, err = decodeChildRows(decoder)
+		} else {
+			err = decoder.Decode(&value)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := row[key]; !ok {
+			keys = append(keys, key)
+		}
+		row[key] = value
+	}
+	// consume the closing brace of the object
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	return row, keys, nil
+}
+
+// decodeChildRows decodes the value of a child rows column, arrays of objects are returned as ChildRows
+// keeping the key order of the objects.
+func decodeChildRows(decoder *json.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if rows, err := decodeOrderedRows(raw); err == nil {
+		return rows, nil
+	}
+	// other values (null, strings, arrays of other values) are decoded as usual and reported by the generator
+	var value interface{}
+	valueDecoder := json.NewDecoder(bytes.NewReader(raw))
+	valueDecoder.UseNumber()
+	if err := valueDecoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// decodeOrderedRows decodes an array of objects, the columns are the keys of the objects in document order.
+func decodeOrderedRows(raw json.RawMessage) (ChildRows, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return ChildRows{}, err
+	}
+	if token != json.Delim('[') {
+		return ChildRows{}, fmt.Errorf("expected an array of objects")
+	}
+	rows := ChildRows{Rows: []map[string]interface{}{}}
+	seen := make(map[string]bool)
+	for decoder.More() {
+		row, keys, err := decodeObject(decoder)
+		if err != nil {
+			return ChildRows{}, err
+		}
+		rows.Rows = append(rows.Rows, row)
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				rows.Columns = append(rows.Columns, key)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// source returns the columns of the rows read so far.
+func (it *jsonIterator) source() Dataset {
+	return Dataset{Columns: it.columns, Source: SourceJSON, FirstRow: 1}
+}
+
+func (it *jsonIterator) Close() error {
+	return nil
+}
+
+// Stream implementation for CSVLoader, the records are parsed one by one as they are read
+// from Reader (or Content).
+func (c CSVLoader) Stream() (RowIterator, error) {
+	separator := c.Separator
+	if separator == 0 {
+		separator = ','
+	}
+	quote := c.Quote
+	if quote == 0 {
+		quote = '"'
+	}
+	var reader io.Reader = bytes.NewReader(c.Content.Bytes())
+	if c.Reader != nil {
+		reader = c.Reader
+	}
+	records, err := newCSVReader(reader, separator, quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if !c.KeepBOM {
+		if err := records.skipBOM(); err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+	}
+	header, line, err := records.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("csv content has no data")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	columns := make([]string, len(header))
+	for i, column := range header {
+		columns[i] = normalizeColumnName(column, c.ColumnsMapper)
+	}
+	return &csvIterator{records: records, columns: columns, firstRow: line + 1, allowRaggedRows: c.AllowRaggedRows}, nil
+}
+
+type csvIterator struct {
+	records         *csvReader
+	columns         []string
+	firstRow        int
+	line            int
+	allowRaggedRows bool
+}
+
+func (it *csvIterator) source() Dataset {
+	return Dataset{Columns: it.columns, Source: SourceCSV, FirstRow: it.firstRow}
+}
+
+func (it *csvIterator) rowNumber() int {
+	return it.line
+}
+
+func (it *csvIterator) Next() (map[string]interface{}, error) {
+	record, line, err := it.records.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(record) != len(it.columns) && !it.allowRaggedRows {
+		return nil, fmt.Errorf("csv row %d has %d fields, expected %d", line, len(record), len(it.columns))
+	}
+	it.line = line
+	dataRow := make(map[string]interface{}, len(it.columns))
+	for colIndex, column := range it.columns {
+		value := ""
+		if colIndex < len(record) {
+			value = record[colIndex]
+		}
+		dataRow[column] = value
+	}
+	return dataRow, nil
+}
+
+func (it *csvIterator) Close() error {
+	return nil
+}
+
+// Stream implementation for ExcelLoader, the sheet rows are read with the excelize row iterator
+// so that only the current row is kept in memory.
+func (e ExcelLoader) Stream() (RowIterator, error) {
+	f, err := e.open()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := f.Rows(e.SheetName)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to get sheet '%s': %w", e.SheetName, err)
+	}
+	it := &excelIterator{file: f, rows: rows, sheetName: e.SheetName}
+	header, err := it.nextCells()
+	if err != nil {
+		it.Close()
+		if err == io.EOF {
+			return nil, fmt.Errorf("sheet '%s' has no data", e.SheetName)
+		}
+		return nil, err
+	}
+	it.columns = make([]string, len(header))
+	for i, column := range header {
+		it.columns[i] = normalizeColumnName(column, e.ColumnsMapper)
+	}
+	return it, nil
+}
+
+// open opens the workbook of Reader, or of Content when Reader is not set.
+func (e ExcelLoader) open() (*excelize.File, error) {
+	var reader io.Reader = &e.Content
+	if e.Reader != nil {
+		reader = e.Reader
+	}
+	f, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	return f, nil
+}
+
+type excelIterator struct {
+	file      *excelize.File
+	rows      *excelize.Rows
+	sheetName string
+	columns   []string
+	row       int // sheet row of the cells read last
+}
+
+func (it *excelIterator) source() Dataset {
+	return Dataset{Columns: it.columns, Source: SourceExcel, Sheet: it.sheetName, FirstRow: 2}
+}
+
+// nextCells returns the cells of the next sheet row.
+func (it *excelIterator) nextCells() ([]string, error) {
+	if !it.rows.Next() {
+		if err := it.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	it.row++
+	return it.rows.Columns()
+}
+
+func (it *excelIterator) rowNumber() int {
+	return it.row
+}
+
+// Next returns the next row that isn't fully blank.
+func (it *excelIterator) Next() (map[string]interface{}, error) {
+	for {
+		cells, err := it.nextCells()
+		if err != nil {
+			return nil, err
+		}
+		if dataRow, blank := sheetRow(it.columns, cells); !blank {
+			return dataRow, nil
+		}
+	}
+}
+
+func (it *excelIterator) Close() error {
+	rowsErr := it.rows.Close()
+	if err := it.file.Close(); err != nil {
+		return err
+	}
+	return rowsErr
+}
+
+// SeedTo streams the statements of a config to w, rows are read one at a time from StreamLoaders
+// and generated in batches of MaxRowsPerStatement rows (1000 when it is not set) so that neither
+// the loaded data nor the script is held in memory at once. The template is parsed once per call.
+// With CollectErrors the invalid cells of every batch are returned together as CellErrors, nothing
+// more is written to w once a batch has invalid cells.
+// Function configs need the whole dataset and are loaded entirely before their call is written.
+func (s *Seeder) SeedTo(w io.Writer, config SeederConfig) error {
+	rows, err := streamRows(config.Loader)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batchSize := s.MaxRowsPerStatement
+	if batchSize <= 0 {
+		batchSize = defaultStreamBatchSize
+	}
+	if config.FunctionName != "" {
+		batchSize = 0
+	}
+
+	var tmpl *template.Template
+	if config.FunctionName == "" {
+		// the template is parsed once for every batch
+		tmpl, err = s.Generator.ParseTemplate()
+		if err != nil {
+			return err
+		}
+	}
+	numbered, _ := rows.(rowNumberIterator)
+	// the lookups of every batch are registered by the batch itself, rows are streamed
+	seeded := s.newSeededRows(nil, nil)
+
+	var (
+		batch      []map[string]interface{}
+		batchLines []int
+		flushed    bool
+		read       int
+		collected  CellErrors
+		seen       = make(map[string]bool)
+	)
+	// collect keeps the cell errors of a batch when CollectErrors is set, header errors are
+	// reported by every batch and kept once
+	collect := func(err error) error {
+		if err == nil || !s.CollectErrors {
+			return err
+		}
+		var cellErrs CellErrors
+		var cellErr *CellError
+		switch {
+		case errors.As(err, &cellErrs):
+		case errors.As(err, &cellErr):
+			cellErrs = CellErrors{cellErr}
+		default:
+			return err
+		}
+		for _, cellErr := range cellErrs {
+			if !seen[cellErr.Error()] {
+				seen[cellErr.Error()] = true
+				collected = append(collected, cellErr)
+			}
+		}
+		return nil
+	}
+	flush := func() error {
+		flushed = true
+		// the source is read on every batch since the columns of streamed JSON grow with the rows
+		dataset := iteratorSource(rows)
+		dataset.Rows = batch
+		if numbered != nil {
+			dataset.RowNumbers = batchLines
+		} else {
+			// rows of the batch are located after the rows of the previous batches
+			dataset.offset = read - len(batch)
+		}
+		defer func() { batch, batchLines = nil, nil }()
+		if config.FunctionName != "" {
+			script, err := s.generateFunctionCall(batch, config.FunctionName)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(w, script)
+			return err
+		}
+		sqlData, err := s.buildSQLData(config, &dataset, seeded)
+		if err != nil {
+			return err
+		}
+		if len(collected) > 0 {
+			// the script is incomplete already, the remaining batches are only checked
+			return nil
+		}
+		return s.Generator.GenerateWithTemplate(w, tmpl, *sqlData)
+	}
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		read++
+		batch = append(batch, row)
+		if numbered != nil {
+			batchLines = append(batchLines, numbered.rowNumber())
+		}
+		if len(batch) == batchSize {
+			if err := collect(flush()); err != nil {
+				return err
+			}
+		}
+	}
+	if len(batch) > 0 || !flushed {
+		if err := collect(flush()); err != nil {
+			return err
+		}
+	}
+	if len(collected) > 0 {
+		return collected
+	}
+	return nil
+}
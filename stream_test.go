@@ -0,0 +1,197 @@
+package sqlseeder
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// collectRows reads an iterator until io.EOF.
+func collectRows(t *testing.T, rows RowIterator) []map[string]interface{} {
+	defer rows.Close()
+	var data []map[string]interface{}
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			return data
+		}
+		require.NoError(t, err)
+		data = append(data, row)
+	}
+}
+
+func TestExcelLoader_Stream(t *testing.T) {
+	content := newTestWorkbook(t, map[string][][]interface{}{
+		"products": {
+			{"Product Name", "Price", "Category_ID**Categories**Category_Name"},
+			{"Laptop", 999, "Electronics"},
+			{"Mouse"},
+		},
+	}, []string{"products"})
+	loader := ExcelLoader{Content: content, SheetName: "products", ColumnsMapper: map[string]string{"product name": "product_name"}}
+
+	expected, err := ExcelLoader{Content: *bytes.NewBuffer(content.Bytes()), SheetName: "products", ColumnsMapper: loader.ColumnsMapper}.Load()
+	require.NoError(t, err)
+	rows, err := loader.Stream()
+	require.NoError(t, err)
+	require.Equal(t, expected, collectRows(t, rows))
+
+	empty := newTestWorkbook(t, map[string][][]interface{}{"products": {}}, []string{"products"})
+	_, err = ExcelLoader{Content: empty, SheetName: "products"}.Stream()
+	require.EqualError(t, err, "sheet 'products' has no data")
+}
+
+func TestJsonLoader_Stream(t *testing.T) {
+	content := `[{"name": "Laptop", "price": 999.5}, {"name": "Mouse", "specs": {"dpi": 800}}]`
+	expected, err := JsonLoader{Content: *bytes.NewBufferString(content)}.Load()
+	require.NoError(t, err)
+	rows, err := JsonLoader{Content: *bytes.NewBufferString(content)}.Stream()
+	require.NoError(t, err)
+	require.Equal(t, expected, collectRows(t, rows))
+
+	_, err = JsonLoader{Content: *bytes.NewBufferString(`{"name": "Laptop"}`)}.Stream()
+	require.Error(t, err)
+}
+
+func TestJsonLoader_StreamChildRows(t *testing.T) {
+	content := `[{"order_number": "A1", "order_lines>order_number": [{"qty": 2, "sku": "X"}, {"note": "spare", "qty": 1}]}, {"order_number": "A2", "order_lines>order_number": "NULL"}]`
+	rows, err := JsonLoader{Content: *bytes.NewBufferString(content)}.Stream()
+	require.NoError(t, err)
+	data := collectRows(t, rows)
+	require.Equal(t, ChildRows{
+		Rows: []map[string]interface{}{
+			{"qty": json.Number("2"), "sku": "X"},
+			{"note": "spare", "qty": json.Number("1")},
+		},
+		Columns: []string{"qty", "sku", "note"},
+	}, data[0]["order_lines>order_number"])
+	require.Equal(t, "NULL", data[1]["order_lines>order_number"])
+}
+
+func TestJsonLoader_StreamReader(t *testing.T) {
+	reader, writer := io.Pipe()
+	second := make(chan struct{})
+	go func() {
+		writer.Write([]byte(`[{"name": "Laptop"}, `))
+		<-second
+		writer.Write([]byte(`{"name": "Mouse"}]`))
+		writer.Close()
+	}()
+
+	rows, err := JsonLoader{Reader: reader}.Stream()
+	require.NoError(t, err)
+	// the first row is decoded before the rest of the array is written
+	row, err := rows.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"name": "Laptop"}, row)
+	close(second)
+	require.Equal(t, []map[string]interface{}{{"name": "Mouse"}}, collectRows(t, rows))
+}
+
+func TestCSVLoader_Stream(t *testing.T) {
+	content := "\uFEFFname,notes\nLaptop,\"multi\nline\"\n\nMouse,\n"
+	expected, err := CSVLoader{Content: *bytes.NewBufferString(content)}.Load()
+	require.NoError(t, err)
+	rows, err := CSVLoader{Content: *bytes.NewBufferString(content)}.Stream()
+	require.NoError(t, err)
+	require.Equal(t, expected, collectRows(t, rows))
+
+	_, err = CSVLoader{Content: *bytes.NewBufferString("")}.Stream()
+	require.EqualError(t, err, "csv content has no data")
+}
+
+func TestCSVLoader_StreamReader(t *testing.T) {
+	reader, writer := io.Pipe()
+	second := make(chan struct{})
+	go func() {
+		writer.Write([]byte("name\nLaptop\n"))
+		<-second
+		writer.Write([]byte("Mouse\n"))
+		writer.Close()
+	}()
+
+	rows, err := CSVLoader{Reader: reader}.Stream()
+	require.NoError(t, err)
+	// the first record is parsed before the rest of the file is written
+	row, err := rows.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"name": "Laptop"}, row)
+	close(second)
+	require.Equal(t, []map[string]interface{}{{"name": "Mouse"}}, collectRows(t, rows))
+}
+
+func TestExcelLoader_StreamReader(t *testing.T) {
+	content := newTestWorkbook(t, map[string][][]interface{}{
+		"products": {{"product_name"}, {"Laptop"}, {"Mouse"}},
+	}, []string{"products"})
+
+	rows, err := ExcelLoader{Reader: bytes.NewReader(content.Bytes()), SheetName: "products"}.Stream()
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{"product_name": "Laptop"}, {"product_name": "Mouse"}}, collectRows(t, rows))
+
+	data, err := ExcelLoader{Reader: bytes.NewReader(content.Bytes()), SheetName: "products"}.Load()
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+}
+
+func TestSeeder_SeedTo(t *testing.T) {
+	sheet := [][]interface{}{{"product_name", "tag_id***product_tags***tags***tag_name***product_name"}}
+	for _, name := range []string{"p1", "p2", "p3", "p4", "p5"} {
+		sheet = append(sheet, []interface{}{name, "a|b"})
+	}
+	content := newTestWorkbook(t, map[string][][]interface{}{"products": sheet}, []string{"products"})
+
+	var script bytes.Buffer
+	streamingSeeder := NewSeeder(SeederConfigInit{MaxRowsPerStatement: 2})
+	err := streamingSeeder.SeedTo(&script, SeederConfig{
+		Loader:     ExcelLoader{Content: content, SheetName: "products"},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	require.NoError(t, err)
+	result := script.String()
+	require.Equal(t, 3, strings.Count(result, "INSERT INTO public.products"))
+	require.Equal(t, 5, strings.Count(result, "INSERT INTO product_tags"))
+	for _, name := range []string{"'p1'", "'p5'"} {
+		require.Contains(t, result, name)
+	}
+	// every batch of join rows comes after the batch of products it looks up
+	require.Less(t, strings.Index(result, "INSERT INTO public.products"), strings.Index(result, "INSERT INTO product_tags"))
+
+	// the template is read and parsed once for the three batches
+	templates := &countingFS{FS: fstest.MapFS{"insert.tmpl": {Data: []byte(defaultTemplate)}}}
+	script.Reset()
+	err = NewSeeder(SeederConfigInit{MaxRowsPerStatement: 2, TemplateFS: templates, TemplatePath: "insert.tmpl"}).SeedTo(&script, SeederConfig{
+		Loader:     ExcelLoader{Content: *bytes.NewBuffer(content.Bytes()), SheetName: "products"},
+		SchemaName: "public",
+		TableName:  "products",
+	})
+	require.NoError(t, err)
+	require.Equal(t, result, script.String())
+	require.Equal(t, 1, templates.opened)
+
+	script.Reset()
+	err = seeder.SeedTo(&script, SeederConfig{
+		Loader:       JsonLoader{Content: *bytes.NewBufferString(`[{"name": "a"}, {"name": "b"}]`)},
+		FunctionName: "seed_items",
+	})
+	require.NoError(t, err)
+	require.Equal(t, `SELECT seed_items('[{"name":"a"},{"name":"b"}]'::JSONB);`, script.String())
+}
+
+// countingFS counts the files opened from FS.
+type countingFS struct {
+	fs.FS
+	opened int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	c.opened++
+	return c.FS.Open(name)
+}